@@ -5,7 +5,7 @@
 
 package CharClass
 
-// Character classes, used to determine the specific kind of character that is read by GetChar.
+// Character classes, used to determine the specific kind of character that is read by the Lexer.
 const (
 	// LOWLETTER To distinguish lower case letters in variables.
 	LOWLETTER = iota
@@ -31,6 +31,20 @@ const (
 	UNDEFINED
 	// ENDOFLINE Indicates that the last read character is an end of line.
 	ENDOFLINE
-	// SPACE To distinguish spaces
+	// SPACE To distinguish spaces and tabs
 	SPACE
+	// COMMA To distinguish the separator between the assumptions in a context.
+	COMMA
+	// TURNSTILE1 To distinguish the first part of an ASCII turnstile, the '|' in "|-".
+	TURNSTILE1
+	// TURNSTILE To distinguish the turnstile '⊢' between a context and an expression.
+	TURNSTILE
+	// ARROW To distinguish the Unicode function type arrow '→'.
+	ARROW
+	// NEWLINE To distinguish the end of a line that is continued on the next line.
+	NEWLINE
+	// COMMENT To distinguish the '#' that starts a comment, a comment can also start with "--".
+	COMMENT
+	// EQUALS To distinguish the '=' between the name and the expression of a definition.
+	EQUALS
 )
@@ -0,0 +1,198 @@
+/*
+ * Parser and Lexical Analyser DeBruijn.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package DeBruijn
+
+import (
+	"Parser-TypeChecking/Evaluator"
+	"Parser-TypeChecking/Parsetree"
+	"Parser-TypeChecking/Tokens"
+	"strconv"
+	"strings"
+)
+
+// Kinds of nameless terms.
+const (
+	// Bound A variable bound by a lambda, denoted by the amount of lambdas in between.
+	Bound = iota
+	// Free A variable that is not bound by any lambda, it keeps its name.
+	Free
+	// Lambda An abstraction, its variable is not named.
+	Lambda
+	// Application Application of the first child to the second child.
+	Application
+)
+
+// Term
+// Nameless representation of an expression, bound variables are replaced by de Bruijn indices.
+type Term struct {
+	// Kind of the term.
+	Kind int
+	// Index of a Bound variable, 0 refers to the closest lambda.
+	Index int
+	// Name of a Free variable, for a Lambda the name of its variable is kept to convert back.
+	Name string
+	// Type annotated on a Lambda, nil if the lambda has no annotation.
+	Type *parsetree.Term
+	// Children the body of a Lambda, the function and argument of an Application.
+	Children []*Term
+}
+
+// FromTerm
+// Converts the parsed expression to its nameless representation.
+// expression: The parsed expression.
+func FromTerm(expression *parsetree.Term) *Term {
+	return fromTerm(expression, nil)
+}
+
+// fromTerm
+// Converts the expression, scope contains the names of the enclosing lambda variables, innermost last.
+func fromTerm(expression *parsetree.Term, scope []string) *Term {
+	switch expression.Token {
+	case Tokens.TokenLambda:
+		var variable = expression.Children[0]
+		var lambda = Term{Kind: Lambda, Name: variable.Lexeme}
+		if len(variable.Children) > 0 {
+			lambda.Type = variable.Children[0]
+		}
+		lambda.Children = []*Term{fromTerm(expression.Children[1], append(scope[:len(scope):len(scope)], variable.Lexeme))}
+		return &lambda
+	case Tokens.Application:
+		return &Term{Kind: Application, Children: []*Term{fromTerm(expression.Children[0], scope),
+			fromTerm(expression.Children[1], scope)}}
+	}
+	for i := len(scope) - 1; i >= 0; i-- {
+		if scope[i] == expression.Lexeme {
+			return &Term{Kind: Bound, Index: len(scope) - 1 - i}
+		}
+	}
+	return &Term{Kind: Free, Name: expression.Lexeme}
+}
+
+// ToTerm
+// Converts the nameless term back to an expression. Lambda variables get their original name where possible,
+// a number is appended to the name if it would capture another variable.
+func (term *Term) ToTerm() *parsetree.Term {
+	var avoid = map[string]bool{}
+	term.freeNames(avoid)
+	return term.toTerm(nil, avoid)
+}
+
+// freeNames
+// Adds the names of all free variables in the term to names.
+func (term *Term) freeNames(names map[string]bool) {
+	if term.Kind == Free {
+		names[term.Name] = true
+	}
+	for _, child := range term.Children {
+		child.freeNames(names)
+	}
+}
+
+// toTerm
+// Converts the term back, scope contains the names given to the enclosing lambda variables, innermost last.
+// avoid contains the free variables and the names in scope.
+func (term *Term) toTerm(scope []string, avoid map[string]bool) *parsetree.Term {
+	switch term.Kind {
+	case Bound:
+		return parsetree.NewVariable(Tokens.TokenVariable, scope[len(scope)-1-term.Index], Tokens.Span{})
+	case Free:
+		return parsetree.NewVariable(Tokens.TokenVariable, term.Name, Tokens.Span{})
+	case Lambda:
+		var name = Evaluator.FreshName(term.Name, avoid)
+		var variable = parsetree.NewVariable(Tokens.TokenVariable, name, Tokens.Span{})
+		if term.Type != nil {
+			variable.Children = []*parsetree.Term{term.Type}
+		}
+		avoid[name] = true
+		var body = term.Children[0].toTerm(append(scope[:len(scope):len(scope)], name), avoid)
+		delete(avoid, name)
+		return parsetree.NewLambda(Tokens.Span{}, variable, body)
+	}
+	return parsetree.NewApplication(term.Children[0].toTerm(scope, avoid), term.Children[1].toTerm(scope, avoid))
+}
+
+// Equal
+// Returns whether both nameless terms are the same, the names of lambda variables are ignored.
+// Annotated types have to be the same up to redundant brackets.
+// other: Term to compare with.
+func (term *Term) Equal(other *Term) bool {
+	if term.Kind != other.Kind || len(term.Children) != len(other.Children) {
+		return false
+	}
+	switch term.Kind {
+	case Bound:
+		return term.Index == other.Index
+	case Free:
+		return term.Name == other.Name
+	case Lambda:
+		if (term.Type == nil) != (other.Type == nil) ||
+			(term.Type != nil && term.Type.String() != other.Type.String()) {
+			return false
+		}
+	}
+	for i := range term.Children {
+		if !term.Children[i].Equal(other.Children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// AlphaEquivalent
+// Returns whether both expressions are the same up to the names of bound variables.
+// first: The first expression.
+// second: The second expression.
+func AlphaEquivalent(first *parsetree.Term, second *parsetree.Term) bool {
+	return FromTerm(first).Equal(FromTerm(second))
+}
+
+// String
+// Prints the nameless term, e.g. \ \ 1 0 for \x \y x y.
+func (term *Term) String() string {
+	var output strings.Builder
+	term.write(&output)
+	return output.String()
+}
+
+// write
+// Writes the term to output, used by String.
+func (term *Term) write(output *strings.Builder) {
+	switch term.Kind {
+	case Bound:
+		output.WriteString(strconv.Itoa(term.Index))
+	case Free:
+		output.WriteString(term.Name)
+	case Lambda:
+		output.WriteString("\\")
+		if term.Type != nil {
+			output.WriteString("^")
+			if term.Type.Token == Tokens.TokenFunction {
+				output.WriteString("(" + term.Type.String() + ")")
+			} else {
+				output.WriteString(term.Type.String())
+			}
+		}
+		output.WriteString(" ")
+		term.Children[0].write(output)
+	case Application:
+		var function, argument = term.Children[0], term.Children[1]
+		writeBracketed(output, function, function.Kind == Lambda)
+		output.WriteString(" ")
+		writeBracketed(output, argument, argument.Kind == Lambda || argument.Kind == Application)
+	}
+}
+
+// writeBracketed
+// Writes the term to output, surrounded by brackets if needed.
+func writeBracketed(output *strings.Builder, term *Term, needsBrackets bool) {
+	if needsBrackets {
+		output.WriteString("(")
+		term.write(output)
+		output.WriteString(")")
+	} else {
+		term.write(output)
+	}
+}
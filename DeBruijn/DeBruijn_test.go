@@ -0,0 +1,86 @@
+/*
+ * Parser and Lexical Analyser DeBruijn_test.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package DeBruijn
+
+import (
+	"Parser-TypeChecking/Parser"
+	"Parser-TypeChecking/Parsetree"
+	"testing"
+)
+
+// parseExpression
+// Parses the expression of a line without type, the test fails if it cannot be parsed.
+// input: The expression.
+func parseExpression(t *testing.T, input string) *parsetree.Term {
+	t.Helper()
+	var judgement, err = parser.ParseWith(input, parser.Options{TypeOptional: true})
+	if err != nil {
+		t.Fatalf("ParseWith(%q) = %v", input, err)
+	}
+	return judgement.Expression
+}
+
+// TestRoundTrip
+// Converts expressions to their nameless representation and back, the result is alpha-equivalent to the expression.
+func TestRoundTrip(t *testing.T) {
+	var tests = []struct {
+		expression string
+		nameless   string
+		// The expression converted back.
+		want string
+	}{
+		{`x`, `x`, `x`},
+		{`\x x`, `\ 0`, `\x x`},
+		{`\x \y x y`, `\ \ 1 0`, `\x \y x y`},
+		{`\x \x x`, `\ \ 0`, `\x \x1 x1`},
+		{`\x^A \y^(A->B) y x`, `\^A \^(A -> B) 0 1`, `\x^A \y^(A -> B) y x`},
+		{`\y x y`, `\ x 0`, `\y x y`},
+		// The bound x is renamed, it would capture the free x otherwise.
+		{`(\x x) x`, `(\ 0) x`, `(\x1 x1) x`},
+	}
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			var expression = parseExpression(t, test.expression)
+			var nameless = FromTerm(expression)
+			if got := nameless.String(); got != test.nameless {
+				t.Errorf("FromTerm(%s) = %s, want %s", test.expression, got, test.nameless)
+			}
+			var back = nameless.ToTerm()
+			if got := back.String(); got != test.want {
+				t.Errorf("FromTerm(%s).ToTerm() = %s, want %s", test.expression, got, test.want)
+			}
+			if !AlphaEquivalent(back, expression) {
+				t.Errorf("FromTerm(%s).ToTerm() = %s is not alpha-equivalent", test.expression, back)
+			}
+		})
+	}
+}
+
+// TestAlphaEquivalent
+// Compares pairs of expressions up to the names of bound variables.
+func TestAlphaEquivalent(t *testing.T) {
+	var tests = []struct {
+		first  string
+		second string
+		want   bool
+	}{
+		{`\x x`, `\y y`, true},
+		{`\x \y x`, `\a \b a`, true},
+		{`\x \y x`, `\x \y y`, false},
+		{`\x y`, `\x z`, false},
+		{`\x^A x`, `\y^(A) y`, true},
+		{`\x^A x`, `\y^B y`, false},
+		{`\x^A x`, `\x x`, false},
+	}
+	for _, test := range tests {
+		t.Run(test.first+" "+test.second, func(t *testing.T) {
+			var got = AlphaEquivalent(parseExpression(t, test.first), parseExpression(t, test.second))
+			if got != test.want {
+				t.Errorf("AlphaEquivalent(%s, %s) = %v, want %v", test.first, test.second, got, test.want)
+			}
+		})
+	}
+}
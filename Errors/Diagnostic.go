@@ -0,0 +1,121 @@
+/*
+ * Parser and Lexical Analyser Diagnostic.go
+ * Copyright (C) 2021-2023 Bas Blokzijl, Leiden, The Netherlands.
+ */
+
+package Errors
+
+import (
+	"Parser-TypeChecking/Tokens"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ANSI escape codes used when colouring diagnostics.
+const (
+	colourError  = "\033[1;31m"
+	colourMarker = "\033[1;32m"
+	colourReset  = "\033[0m"
+)
+
+// Located
+// Implemented by errors that know which part of the analysed line caused them.
+type Located interface {
+	error
+	Location() Tokens.Span
+}
+
+// Location
+// Returns the span of the offending token.
+func (err *SyntaxError) Location() Tokens.Span {
+	return err.Span
+}
+
+// Location
+// Returns the span of the subterm that could not be typed.
+func (err *TypeError) Location() Tokens.Span {
+	return err.Span
+}
+
+// Location
+// Returns the span of the path that could not be imported.
+func (err *ImportError) Location() Tokens.Span {
+	return err.Span
+}
+
+// Marker
+// Returns the "^~~~" line that points at span when printed underneath line.
+// Tabs in front of the span are copied so the marker lines up with the source.
+// line: The analysed line.
+// span: The part of the line to mark.
+func Marker(line []rune, span Tokens.Span) string {
+	var marker strings.Builder
+	for i := 0; i < span.Start && i < len(line); i++ {
+		if line[i] == '\t' {
+			marker.WriteRune('\t')
+		} else {
+			marker.WriteRune(' ')
+		}
+	}
+	for i := len(line); i < span.Start; i++ {
+		marker.WriteRune(' ')
+	}
+	marker.WriteRune('^')
+	for i := span.Start + 1; i < span.End; i++ {
+		marker.WriteRune('~')
+	}
+	return marker.String()
+}
+
+// Render
+// Formats err as a diagnostic: a "file:line:column: message" header, the analysed line
+// and a marker underneath the offending part. Errors without a location only get the header.
+// If the input consists of several lines, only the line that contains the start of the error is printed.
+// err: The error to report.
+// filename: Name of the input file, may be empty.
+// line: The analysed input.
+// colour: Whether to use ANSI colours.
+func Render(err error, filename string, line []rune, colour bool) string {
+	var located Located
+	if !errors.As(err, &located) {
+		return err.Error()
+	}
+	var span = located.Location()
+	line, span = physicalLine(line, span)
+	var header = fmt.Sprintf("%d:%d: ", span.Line, span.Start+1)
+	if filename != "" {
+		header = filename + ":" + header
+	}
+	var message = err.Error()
+	var marker = Marker(line, span)
+	if colour {
+		message = colourError + message + colourReset
+		marker = colourMarker + marker + colourReset
+	}
+	return header + message + "\n" + string(line) + "\n" + marker
+}
+
+// physicalLine
+// Returns the line of the input that contains the start of span, and span relative to that line.
+// A span that continues on the next line is cut off at the end of the line.
+// input: The analysed input, lines are separated by '\n'.
+// span: Position in the input.
+func physicalLine(input []rune, span Tokens.Span) ([]rune, Tokens.Span) {
+	var start = 0
+	for i := 0; i < span.Start && i < len(input); i++ {
+		if input[i] == '\n' {
+			start = i + 1
+		}
+	}
+	var end = start
+	for end < len(input) && input[end] != '\n' {
+		end++
+	}
+	span.Start -= start
+	span.End -= start
+	if span.End > end-start && span.Start < end-start {
+		span.End = end - start
+	}
+	return input[start:end], span
+}
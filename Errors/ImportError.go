@@ -0,0 +1,25 @@
+/*
+ * Parser and Lexical Analyser ImportError.go
+ * Copyright (C) 2021-2023 Bas Blokzijl, Leiden, The Netherlands.
+ */
+
+package Errors
+
+import (
+	"Parser-TypeChecking/Tokens"
+)
+
+// ImportError
+// Describes why an imported file could not be loaded, e.g. it does not exist or it imports itself.
+type ImportError struct {
+	// Message human readable description of the error.
+	Message string
+	// Span of the path on the import line.
+	Span Tokens.Span
+}
+
+// Error
+// Returns the message in the same format the command line application reports it.
+func (err *ImportError) Error() string {
+	return "Import error: " + err.Message
+}
@@ -0,0 +1,77 @@
+/*
+ * Parser and Lexical Analyser SyntaxError.go
+ * Copyright (C) 2021-2023 Bas Blokzijl, Leiden, The Netherlands.
+ */
+
+package Errors
+
+import (
+	"Parser-TypeChecking/Tokens"
+)
+
+// Kinds of syntax errors, used to distinguish the reason the LexicalAnalyser or parser rejected a line.
+const (
+	// UnknownCharacter A character that has no syntactical meaning.
+	UnknownCharacter = iota
+	// InvalidFunction A malformed function type, e.g. a '>' without '-'.
+	InvalidFunction
+	// InvalidVariable A variable that does not start with a letter.
+	InvalidVariable
+	// ExpectedVariable A lambda that is not followed by a <lvar>.
+	ExpectedVariable
+	// ExpectedFunction Two <uvar> without a '->' in between.
+	ExpectedFunction
+	// UnbalancedBrackets A closing bracket without opening bracket or the other way around.
+	UnbalancedBrackets
+	// EmptyExpression An <expr> or <type> that was required but not found.
+	EmptyExpression
+	// ExpectedJudgement A missing or duplicate ':'.
+	ExpectedJudgement
+	// InvalidTypeExpression A token that cannot be part of a <type>.
+	InvalidTypeExpression
+	// InvalidExpression A token that cannot be part of an <expr>.
+	InvalidExpression
+	// InvalidTurnstile A malformed turnstile, e.g. a '|' without '-'.
+	InvalidTurnstile
+	// InvalidDefinition A definition without name or '=', or with a context.
+	InvalidDefinition
+	// InvalidImport An import without a path in double quotes.
+	InvalidImport
+	// InvalidContinuation A '\' at the end of a line that is not followed by another line.
+	InvalidContinuation
+)
+
+// SyntaxError
+// Describes why a line could not be lexically analysed or parsed.
+type SyntaxError struct {
+	// Kind of the error, one of the constants above.
+	Kind int
+	// Message human readable description of the error.
+	Message string
+	// Rune the offending character, 0 if the error occurred at the end of the line.
+	Rune rune
+	// Offset index of the offending character in the analysed line.
+	Offset int
+	// Span of the offending token.
+	Span Tokens.Span
+}
+
+// NewSyntaxError
+// Creates a SyntaxError for the token at span in line.
+// kind: The kind of syntax error.
+// message: Description of the error.
+// line: The analysed line.
+// span: Position of the offending token in the line.
+func NewSyntaxError(kind int, message string, line []rune, span Tokens.Span) *SyntaxError {
+	var err = SyntaxError{kind, message, 0, span.Start, span}
+	if span.Start >= 0 && span.Start < len(line) {
+		err.Rune = line[span.Start]
+	}
+	return &err
+}
+
+// Error
+// Returns the message in the same format the command line application reports it.
+func (err *SyntaxError) Error() string {
+	return "Syntax error: " + err.Message
+}
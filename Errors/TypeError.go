@@ -0,0 +1,25 @@
+/*
+ * Parser and Lexical Analyser TypeError.go
+ * Copyright (C) 2021-2023 Bas Blokzijl, Leiden, The Netherlands.
+ */
+
+package Errors
+
+import (
+	"Parser-TypeChecking/Tokens"
+)
+
+// TypeError
+// Describes why an expression could not be typed.
+type TypeError struct {
+	// Message human readable description of the error.
+	Message string
+	// Span of the subterm that could not be typed.
+	Span Tokens.Span
+}
+
+// Error
+// Returns the message in the same format the command line application reports it.
+func (err *TypeError) Error() string {
+	return "Typecheck error: " + err.Message
+}
@@ -0,0 +1,131 @@
+/*
+ * Parser and Lexical Analyser Evaluator.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package Evaluator
+
+import (
+	"Parser-TypeChecking/Parsetree"
+	"Parser-TypeChecking/Tokens"
+	"fmt"
+)
+
+// Reduction strategies, they decide which redex is contracted in a step.
+const (
+	// NormalOrder Contracts the leftmost outermost redex, also underneath lambdas.
+	NormalOrder = iota
+	// ApplicativeOrder Contracts the leftmost innermost redex, also underneath lambdas.
+	ApplicativeOrder
+	// CallByName Contracts the leftmost outermost redex, but never underneath a lambda or in an argument.
+	CallByName
+	// CallByValue Reduces the argument to a lambda before contracting a redex, but never underneath a lambda.
+	CallByValue
+)
+
+// StrategyNames maps the names used on the commandline to the strategies.
+var StrategyNames = map[string]int{
+	"normal":      NormalOrder,
+	"applicative": ApplicativeOrder,
+	"cbn":         CallByName,
+	"cbv":         CallByValue,
+}
+
+// ParseStrategy
+// Returns the strategy for the provided name, see StrategyNames.
+// name: Name of the strategy.
+func ParseStrategy(name string) (int, error) {
+	var strategy, found = StrategyNames[name]
+	if !found {
+		return 0, fmt.Errorf("unknown reduction strategy %q, use normal, applicative, cbn or cbv", name)
+	}
+	return strategy, nil
+}
+
+// StepLimitError
+// Returned by Evaluate if the expression did not reach a normal form within the step limit.
+type StepLimitError struct {
+	// Limit the maximum amount of steps.
+	Limit int
+}
+
+// Error
+// Describes the reached limit.
+func (err *StepLimitError) Error() string {
+	return fmt.Sprintf("no normal form found within %d steps", err.Limit)
+}
+
+// Contract
+// Performs the beta reduction of a redex (\x e) a, which results in e with x replaced by a.
+// redex: An application of a lambda.
+func Contract(redex *parsetree.Term) *parsetree.Term {
+	var lambda, argument = redex.Children[0], redex.Children[1]
+	return Substitute(lambda.Children[1], lambda.Children[0].Lexeme, argument)
+}
+
+// isRedex
+// Returns whether the expression is an application of a lambda.
+func isRedex(expression *parsetree.Term) bool {
+	return expression.Token == Tokens.Application && expression.Children[0].Token == Tokens.TokenLambda
+}
+
+// Step
+// Performs a single beta reduction according to the strategy.
+// Returns the reduced expression and whether a redex was found.
+// expression: The expression to reduce.
+// strategy: One of the reduction strategies.
+func Step(expression *parsetree.Term, strategy int) (*parsetree.Term, bool) {
+	switch expression.Token {
+	case Tokens.TokenLambda:
+		if strategy == CallByName || strategy == CallByValue {
+			// Weak strategies, a lambda is a value.
+			return expression, false
+		}
+		var body, reduced = Step(expression.Children[1], strategy)
+		if !reduced {
+			return expression, false
+		}
+		return parsetree.NewLambda(expression.Span, expression.Children[0], body), true
+	case Tokens.Application:
+		var function, argument = expression.Children[0], expression.Children[1]
+		if isRedex(expression) && (strategy == NormalOrder || strategy == CallByName) {
+			return Contract(expression), true
+		}
+		if reducedFunction, reduced := Step(function, strategy); reduced {
+			return parsetree.NewApplication(reducedFunction, argument), true
+		}
+		if strategy != CallByName {
+			if reducedArgument, reduced := Step(argument, strategy); reduced {
+				return parsetree.NewApplication(function, reducedArgument), true
+			}
+		}
+		// Call by value only substitutes values, a redex with an argument that cannot be reduced to a lambda is stuck.
+		if isRedex(expression) && (strategy != CallByValue || argument.Token == Tokens.TokenLambda) {
+			return Contract(expression), true
+		}
+	}
+	return expression, false
+}
+
+// Evaluate
+// Reduces the expression according to the strategy until no more redexes are found.
+// Returns all intermediate expressions, starting with the provided expression, the last one is the normal form
+// for the strategy. A StepLimitError is returned together with the steps if limit steps were not enough.
+// expression: The expression to evaluate.
+// strategy: One of the reduction strategies.
+// limit: Maximum amount of reduction steps.
+func Evaluate(expression *parsetree.Term, strategy int, limit int) ([]*parsetree.Term, error) {
+	var steps = []*parsetree.Term{expression}
+	for i := 0; i < limit; i++ {
+		var reduced, found = Step(expression, strategy)
+		if !found {
+			return steps, nil
+		}
+		expression = reduced
+		steps = append(steps, expression)
+	}
+	if _, found := Step(expression, strategy); found {
+		return steps, &StepLimitError{limit}
+	}
+	return steps, nil
+}
@@ -0,0 +1,72 @@
+/*
+ * Parser and Lexical Analyser Evaluator_test.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package Evaluator
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestEvaluate
+// Evaluates expressions with every strategy and compares the normal form and the amount of steps.
+func TestEvaluate(t *testing.T) {
+	const omega = `(\z z z) (\z z z)`
+	var tests = []struct {
+		expression string
+		strategy   string
+		// The last expression, the normal form unless the step limit is reached.
+		want  string
+		steps int
+		// Whether the step limit of 10 is reached.
+		limit bool
+	}{
+		{`(\x x) (\y (\z z) y)`, "normal", `\y y`, 2, false},
+		{`(\x x) (\y (\z z) y)`, "applicative", `\y y`, 2, false},
+		{`(\x x) (\y (\z z) y)`, "cbn", `\y (\z z) y`, 1, false},
+		{`(\x x) (\y (\z z) y)`, "cbv", `\y (\z z) y`, 1, false},
+		{`(\x \y y) (` + omega + `)`, "normal", `\y y`, 1, false},
+		{`(\x \y y) (` + omega + `)`, "cbn", `\y y`, 1, false},
+		{`(\x \y y) (` + omega + `)`, "applicative", `(\x \y y) (` + omega + `)`, 10, true},
+		{`(\x \y y) (` + omega + `)`, "cbv", `(\x \y y) (` + omega + `)`, 10, true},
+		{`(\f \x f (f x)) (\y y) z`, "normal", `z`, 4, false},
+		{`(\x x) ((\y y) (\z z))`, "cbv", `\z z`, 2, false},
+		// Call by value does not substitute an argument that cannot be reduced to a lambda.
+		{`(\x^A y) (z w)`, "cbv", `(\x^A y) (z w)`, 0, false},
+		{`(\x^A y) (z w)`, "cbn", `y`, 1, false},
+	}
+	for _, test := range tests {
+		t.Run(test.strategy+" "+test.expression, func(t *testing.T) {
+			var strategy, err = ParseStrategy(test.strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var steps, evalErr = Evaluate(parseExpression(t, test.expression), strategy, 10)
+			var limit *StepLimitError
+			if reached := errors.As(evalErr, &limit); reached != test.limit {
+				t.Fatalf("Evaluate(%s) = %v, want step limit %v", test.expression, evalErr, test.limit)
+			}
+			if got := steps[len(steps)-1].String(); got != test.want {
+				t.Errorf("Evaluate(%s) ends with %s, want %s", test.expression, got, test.want)
+			}
+			if len(steps)-1 != test.steps {
+				t.Errorf("Evaluate(%s) took %d steps, want %d", test.expression, len(steps)-1, test.steps)
+			}
+		})
+	}
+}
+
+// TestParseStrategy
+// Only the names in StrategyNames are accepted.
+func TestParseStrategy(t *testing.T) {
+	for name, want := range StrategyNames {
+		if got, err := ParseStrategy(name); err != nil || got != want {
+			t.Errorf("ParseStrategy(%q) = %d, %v, want %d", name, got, err, want)
+		}
+	}
+	if _, err := ParseStrategy("lazy"); err == nil {
+		t.Error("ParseStrategy(\"lazy\") succeeded, want an error")
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Parser and Lexical Analyser Substitution.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package Evaluator
+
+import (
+	"Parser-TypeChecking/Globals"
+	"Parser-TypeChecking/Parsetree"
+	"Parser-TypeChecking/Tokens"
+	"strconv"
+)
+
+// FreeVariables
+// Returns the names of all variables in the expression that are not bound by a lambda.
+// expression: The expression to search.
+func FreeVariables(expression *parsetree.Term) map[string]bool {
+	var free = map[string]bool{}
+	collectFree(expression, map[string]int{}, free)
+	return free
+}
+
+// collectFree
+// Adds the free variables of expression to free.
+// bound: How often every variable is bound by the lambdas around expression.
+func collectFree(expression *parsetree.Term, bound map[string]int, free map[string]bool) {
+	switch expression.Token {
+	case Tokens.TokenVariable:
+		if bound[expression.Lexeme] == 0 {
+			free[expression.Lexeme] = true
+		}
+	case Tokens.TokenLambda:
+		var name = expression.Children[0].Lexeme
+		bound[name]++
+		collectFree(expression.Children[1], bound, free)
+		bound[name]--
+	case Tokens.Application:
+		collectFree(expression.Children[0], bound, free)
+		collectFree(expression.Children[1], bound, free)
+	}
+}
+
+// Substitute
+// Replaces all free occurrences of the variable name in expression by value.
+// Bound variables are renamed where needed, so free variables of value are never captured.
+// expression: The expression to substitute in.
+// name: Name of the variable to replace.
+// value: The expression that replaces the variable.
+func Substitute(expression *parsetree.Term, name string, value *parsetree.Term) *parsetree.Term {
+	return substitute(expression, name, value, FreeVariables(value))
+}
+
+// substitute
+// See Substitute, valueFree contains the free variables of value.
+func substitute(expression *parsetree.Term, name string, value *parsetree.Term, valueFree map[string]bool) *parsetree.Term {
+	switch expression.Token {
+	case Tokens.TokenVariable:
+		if expression.Lexeme == name {
+			return value
+		}
+		return expression
+	case Tokens.Application:
+		return parsetree.NewApplication(substitute(expression.Children[0], name, value, valueFree),
+			substitute(expression.Children[1], name, value, valueFree))
+	case Tokens.TokenLambda:
+		var variable, body = expression.Children[0], expression.Children[1]
+		if variable.Lexeme == name {
+			// The variable is shadowed, there are no free occurrences in the body.
+			return expression
+		}
+		if !FreeVariables(body)[name] {
+			return expression
+		}
+		if valueFree[variable.Lexeme] {
+			// Alpha conversion, the bound variable would capture a free variable of value.
+			var avoid = FreeVariables(body)
+			for free := range valueFree {
+				avoid[free] = true
+			}
+			var renamed = Rename(variable, FreshName(variable.Lexeme, avoid))
+			var occurrence = parsetree.NewVariable(Tokens.TokenVariable, renamed.Lexeme, variable.Span)
+			body = substitute(body, variable.Lexeme, occurrence, map[string]bool{renamed.Lexeme: true})
+			variable = renamed
+		}
+		return parsetree.NewLambda(expression.Span, variable, substitute(body, name, value, valueFree))
+	}
+	return expression
+}
+
+// Rename
+// Returns a copy of the variable with the new name, an annotated type is kept.
+// variable: The variable to rename.
+// name: The new name.
+func Rename(variable *parsetree.Term, name string) *parsetree.Term {
+	var renamed = parsetree.NewVariable(variable.Token, name, variable.Span)
+	renamed.Children = variable.Children
+	return renamed
+}
+
+// FreshName
+// Returns name followed by the smallest number such that it is not in avoid, or name itself if possible.
+// name: The preferred name.
+// avoid: Names that cannot be used.
+func FreshName(name string, avoid map[string]bool) string {
+	if !avoid[name] {
+		return name
+	}
+	for i := 1; ; i++ {
+		var candidate = name + strconv.Itoa(i)
+		if !avoid[candidate] {
+			return candidate
+		}
+	}
+}
+
+// Unfold
+// Replaces every free occurrence of a definition in the expression by the expression of the definition,
+// later definitions first since their expressions may refer to earlier definitions.
+// expression: The expression to unfold.
+// environment: The definitions in scope of the expression, nil for none.
+func Unfold(expression *parsetree.Term, environment *Globals.Environment) *parsetree.Term {
+	var definitions = environment.Entries()
+	for i := len(definitions) - 1; i >= 0; i-- {
+		expression = Substitute(expression, definitions[i].Name.Lexeme, definitions[i].Expression)
+	}
+	return expression
+}
@@ -0,0 +1,89 @@
+/*
+ * Parser and Lexical Analyser Substitution_test.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package Evaluator
+
+import (
+	"Parser-TypeChecking/Parser"
+	"Parser-TypeChecking/Parsetree"
+	"testing"
+)
+
+// parseExpression
+// Parses the expression of a line without type, the test fails if it cannot be parsed.
+// input: The expression.
+func parseExpression(t *testing.T, input string) *parsetree.Term {
+	t.Helper()
+	var judgement, err = parser.ParseWith(input, parser.Options{TypeOptional: true})
+	if err != nil {
+		t.Fatalf("ParseWith(%q) = %v", input, err)
+	}
+	return judgement.Expression
+}
+
+// TestSubstitute
+// Substitutes values for variables, bound variables that would capture a free variable of the value are renamed.
+func TestSubstitute(t *testing.T) {
+	var tests = []struct {
+		expression string
+		name       string
+		value      string
+		want       string
+	}{
+		{`x`, "x", `\y y`, `\y y`},
+		{`x y`, "y", `z`, `x z`},
+		{`\x x`, "x", `z`, `\x x`},
+		{`\y x`, "x", `z`, `\y z`},
+		{`\y x`, "x", `y`, `\y1 y`},
+		{`\y \y1 x y y1`, "x", `y`, `\y1 \y11 y y1 y11`},
+		{`\y^A x y`, "x", `y`, `\y1^A y y1`},
+	}
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			var expression = parseExpression(t, test.expression)
+			var got = Substitute(expression, test.name, parseExpression(t, test.value)).String()
+			if got != test.want {
+				t.Errorf("Substitute(%s, %s, %s) = %s, want %s", test.expression, test.name, test.value, got, test.want)
+			}
+		})
+	}
+}
+
+// TestContractCapture
+// Contracting (\x \y x) y renames the bound y instead of capturing the free y.
+func TestContractCapture(t *testing.T) {
+	var got = Contract(parseExpression(t, `(\x \y x) y`)).String()
+	if got != `\y1 y` {
+		t.Errorf("Contract((\\x \\y x) y) = %s, want \\y1 y", got)
+	}
+}
+
+// TestFreeVariables
+// Collects the variables that are not bound by a lambda.
+func TestFreeVariables(t *testing.T) {
+	var tests = []struct {
+		expression string
+		want       []string
+	}{
+		{`x`, []string{"x"}},
+		{`\x x`, nil},
+		{`\x x y`, []string{"y"}},
+		{`(\x x) x`, []string{"x"}},
+		{`\x \y z (x y)`, []string{"z"}},
+	}
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			var got = FreeVariables(parseExpression(t, test.expression))
+			if len(got) != len(test.want) {
+				t.Fatalf("FreeVariables(%s) = %v, want %v", test.expression, got, test.want)
+			}
+			for _, name := range test.want {
+				if !got[name] {
+					t.Errorf("FreeVariables(%s) = %v, want %v", test.expression, got, test.want)
+				}
+			}
+		})
+	}
+}
@@ -11,15 +11,18 @@
 package Globals
 
 import( 
+    "Parser-TypeChecking/LexicalAnalyser"
     ParseTree "Parser-TypeChecking/Parsetree"
+    "Parser-TypeChecking/Tokens"
+    "Parser-TypeChecking/Types"
 )
 
 // VariableType
-// Denotes the Type and Var name both in string form.
+// Denotes the Var name and its Type.
 type VariableType struct {
 	// The token value of this node.
 	VarName string
-	TypeName string
+	Type *Types.Type
 }
 
 type VarTypeList struct{
@@ -30,44 +33,112 @@ type VarTypeList struct{
 // Returns index of variable in the type list exactly if it has a type in this context, else returns -1
 // variableName: The variable of which the type will be searched for.
 func (context *VarTypeList) FindInList (variableName string) int{
-    if (len(context.list) == 0 || len(variableName) == 0){
-        return -1
-    }
-    for i := len(context.list) - 1; i >= 0; i-- {   
-        //if a var name comes up in the list see if entire name is the same.
-        if context.list[i].VarName[0] == variableName[0]{
-            for ind := 0; ind <= len(context.list[i].VarName); ind++ {
-                if ind == len(context.list[i].VarName) { //-1????? todo
-                    return i
-                }else if context.list[i].VarName[ind] != variableName[ind] {
-                    break
-                }
-            }
+    for i := len(context.list) - 1; i >= 0; i-- {
+        if context.list[i].VarName == variableName {
+            return i
         }
-	}
+    }
     return -1
 }
 
 // AddVarType
-// Function to add the provided variable with typeName to context.
+// Function to add the provided variable with its type to context.
 // variableName: Denotes the variable.
-// typeName: The type of the variable.
-func (context *VarTypeList) AddVarType (variableName string, typeName string) {
+// varType: The type of the variable.
+func (context *VarTypeList) AddVarType (variableName string, varType *Types.Type) {
     var newvartype VariableType
     newvartype.VarName = variableName
-    newvartype.TypeName = typeName
+    newvartype.Type = varType
     context.list = append(context.list, newvartype)
 }
 
 // GetLast
-// Function to find rightmost type for variable in context.
+// Function to find rightmost type for variable in context, returns nil if the variable has no type.
 // variableName: Name of the variable.
-func (context *VarTypeList) GetLast (variableName string) string{
+func (context *VarTypeList) GetLast (variableName string) *Types.Type{
     var foundindex = context.FindInList(variableName)
     if (foundindex == -1){
-        return "err"
+        return nil
+    }
+    return context.list[foundindex].Type
+}
+
+// Entries
+// Returns a copy of all variables with their type in context, in order of addition.
+func (context *VarTypeList) Entries () []VariableType{
+    return append([]VariableType(nil), context.list...)
+}
+
+// Len
+// Returns the amount of variables in context, used to restore the context after leaving a scope.
+func (context *VarTypeList) Len () int{
+    return len(context.list)
+}
+
+// Truncate
+// Removes all variables that were added after the context had the provided length,
+// e.g. the variable of a lambda after its body is typed.
+// length: Length of the context when the scope was entered.
+func (context *VarTypeList) Truncate (length int) {
+    context.list = context.list[:length]
+}
+
+// Definition
+// Denotes a named top-level expression and its checked type.
+type Definition struct {
+    // Name of the definition.
+    Name *ParseTree.Term
+    // Expression of the definition.
+    Expression *ParseTree.Term
+    // Type of the definition, its type variables are instantiated freshly on every use.
+    Type *Types.Type
+}
+
+// Environment
+// Global definitions that are in scope of every later judgement, besides the context.
+type Environment struct{
+    list []Definition
+}
+
+// Define
+// Function to add the definition to the environment.
+// name: Name of the definition.
+// expression: Expression of the definition.
+// definedType: The checked type of the expression.
+func (environment *Environment) Define (name *ParseTree.Term, expression *ParseTree.Term, definedType *Types.Type) {
+    environment.list = append(environment.list, Definition{name, expression, definedType})
+}
+
+// Lookup
+// Returns the definition with the provided name, nil if there is none.
+// name: Name of the definition.
+func (environment *Environment) Lookup (name string) *Definition{
+    if environment == nil {
+        return nil
+    }
+    for i := len(environment.list) - 1; i >= 0; i-- {
+        if environment.list[i].Name.Lexeme == name {
+            return &environment.list[i]
+        }
+    }
+    return nil
+}
+
+// Entries
+// Returns a copy of all definitions in the environment, in order of definition.
+func (environment *Environment) Entries () []Definition{
+    if environment == nil {
+        return nil
     }
-    return context.list[foundindex].TypeName
+    return append([]Definition(nil), environment.list...)
+}
+
+// Snapshot
+// Returns an environment with the current definitions that does not see later definitions,
+// it can be read while definitions are added to the original environment.
+func (environment *Environment) Snapshot () *Environment{
+    var snapshot = Environment{environment.list[:len(environment.list):len(environment.list)]}
+    return &snapshot
 }
 
 
@@ -77,36 +148,36 @@ type Vars struct {
     // List of known variables, order is important.
     Context VarTypeList
 
+    // Global definitions, looked up if a variable is not in Context, nil if there are none.
+    Environment *Environment
+
 	// Abstract syntax tree containing all tokens after parsing.
 	Tree ParseTree.ParseTree
 
 	// Used to store the line that is currently analysed.
 	CurrentLine []rune
 
-	// Index on CurrentLine.
-	Index int
+	// Line number of CurrentLine in the input file, 0 if not read from a file.
+	LineNumber int
 
-	// Character currently read from line.
-	ReadChar rune
-
-	// Character class of the currently read character.
-	CharClass int
+	// Token stream of CurrentLine, created by the parser on the first token if nil.
+	Lexer *LexicalAnalyser.Lexer
 
 	// Token read in by lexical analyser.
 	Token int
 
+	// Position of Token in CurrentLine.
+	TokenSpan Tokens.Span
+
 	// PrevToken is the previous token obtained by the LexicalAnalyser.
 	PrevToken int
 
-	// Lexeme that is currently analysed.
+	// Lexeme of Token.
 	Lexeme []rune
 
 	// The last token was a variable and we just encountered a whitespace.
 	ExpectVariable bool
 
-	// The last token was a lambda variable we need ^.
-	ExpectUp bool
-
 	// counter to keep track of the amount of closing and open brackets.
 	CountBrackets int
 }
@@ -0,0 +1,239 @@
+/*
+ * Parser and Lexical Analyser Loader.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+/*
+ * Loads files imported with import "file" at the top of an input file. Only the definitions of an
+ * imported file are analysed, they become available to the importing file. Paths are relative to the
+ * directory of the importing file.
+ */
+
+package Imports
+
+import (
+	"Parser-TypeChecking/Errors"
+	"Parser-TypeChecking/Globals"
+	"Parser-TypeChecking/Parser"
+	"Parser-TypeChecking/Parsetree"
+	"Parser-TypeChecking/TypeChecker"
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Failure
+// Error found while loading the imports of a file, located in the file it came from.
+type Failure struct {
+	// Name of the file the error occurred in, as it was opened.
+	Filename string
+	// The judgement or import the error occurred in, the location of Err is relative to its text.
+	Source parser.Source
+	// The import in the file passed to Load that led to the error, which may be Source itself.
+	Import parser.Source
+	Err    error
+}
+
+// Error
+// Returns the message of the failure preceded by the file and line it came from.
+func (failure *Failure) Error() string {
+	var line = failure.Source.Line
+	var located Errors.Located
+	if errors.As(failure.Err, &located) {
+		line = located.Location().Line
+	}
+	return fmt.Sprintf("%s:%d: %s", failure.Filename, line, failure.Err.Error())
+}
+
+// Render
+// Formats the failure as a diagnostic with the file and line it came from, see Errors.Render.
+// colour: Whether to use ANSI colours.
+func (failure *Failure) Render(colour bool) string {
+	return Errors.Render(failure.Err, failure.Filename, []rune(failure.Source.Text), colour)
+}
+
+// file
+// A file that is being loaded.
+type file struct {
+	// Absolute path of the file, used to recognise a file imported under different names.
+	path string
+	// Name of the file as it was opened.
+	name string
+}
+
+// origin
+// The file and judgement a definition was loaded from.
+type origin struct {
+	filename string
+	source   parser.Source
+}
+
+// Loader
+// Loads the definitions of imported files into Environment. Every file is loaded at most once,
+// hence a file imported by several files does not define its names twice.
+type Loader struct {
+	// Definitions of all imported files, in the order they were loaded.
+	Environment Globals.Environment
+	// Absolute paths of the files that are loaded already.
+	loaded map[string]bool
+	// The files that are being loaded, the first importing file first. Used to detect import cycles.
+	loading []file
+	// Where every definition in Environment was loaded from, by its name.
+	origins map[*parsetree.Term]origin
+}
+
+// NewLoader
+// Creates a loader with an empty environment.
+func NewLoader() *Loader {
+	return &Loader{loaded: map[string]bool{}, origins: map[*parsetree.Term]origin{}}
+}
+
+// ReadLines
+// Returns all lines of the file.
+// filename: Name of the file to read.
+func ReadLines(filename string) ([]string, error) {
+	data, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer data.Close()
+	var lines []string
+	var scanner = bufio.NewScanner(data)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// absolute
+// Returns the absolute path of filename, or the cleaned filename if the working directory is unknown.
+func absolute(filename string) string {
+	if path, err := filepath.Abs(filename); err == nil {
+		return path
+	}
+	return filepath.Clean(filename)
+}
+
+// Load
+// Loads the imports at the top of the file and returns the other judgements of the file, these are not analysed.
+// Returns a failure for every import that cannot be loaded, every import below a judgement and
+// every error in a definition of an imported file.
+// filename: Name of the file, its imports are resolved relative to its directory.
+// sources: The judgements of the file, see parser.Sources.
+func (loader *Loader) Load(filename string, sources []parser.Source) ([]parser.Source, []*Failure) {
+	var path = absolute(filename)
+	loader.loading = append(loader.loading, file{path, filename})
+	defer func() {
+		loader.loading = loader.loading[:len(loader.loading)-1]
+		loader.loaded[path] = true
+	}()
+	var judgements []parser.Source
+	var failures []*Failure
+	for _, source := range sources {
+		if !parser.IsImport(source.Text) {
+			judgements = append(judgements, source)
+			continue
+		}
+		var found []*Failure
+		if len(judgements) > 0 {
+			var _, span, err = parser.ImportPath(source)
+			if err == nil {
+				err = &Errors.ImportError{Message: "Imports have to be at the top of the file", Span: span}
+			}
+			found = []*Failure{{Filename: filename, Source: source, Err: err}}
+		} else {
+			found = loader.include(filename, source)
+		}
+		for _, failure := range found {
+			failure.Import = source
+		}
+		failures = append(failures, found...)
+	}
+	return judgements, failures
+}
+
+// include
+// Loads the file imported by source and defines the definitions in it.
+// filename: Name of the importing file.
+// source: The import.
+func (loader *Loader) include(filename string, source parser.Source) []*Failure {
+	var imported, span, err = parser.ImportPath(source)
+	if err != nil {
+		return []*Failure{{Filename: filename, Source: source, Err: err}}
+	}
+	if !filepath.IsAbs(imported) {
+		imported = filepath.Join(filepath.Dir(filename), imported)
+	}
+	var path = absolute(imported)
+	for i, loading := range loader.loading {
+		if loading.path != path {
+			continue
+		}
+		var cycle []string
+		for _, importing := range loader.loading[i:] {
+			cycle = append(cycle, importing.name)
+		}
+		err = &Errors.ImportError{Message: "Cycle in imports, " + strings.Join(append(cycle, imported), " imports "), Span: span}
+		return []*Failure{{Filename: filename, Source: source, Err: err}}
+	}
+	if loader.loaded[path] {
+		return nil
+	}
+	lines, err := ReadLines(imported)
+	if err != nil {
+		err = &Errors.ImportError{Message: "Cannot read " + imported + ", " + unwrapPathError(err), Span: span}
+		return []*Failure{{Filename: filename, Source: source, Err: err}}
+	}
+	var judgements, failures = loader.Load(imported, parser.Sources(lines))
+	for _, judgement := range judgements {
+		if !parser.IsDefinition(judgement.Text) {
+			continue
+		}
+		if err = loader.define(imported, judgement); err != nil {
+			failures = append(failures, &Failure{Filename: imported, Source: judgement, Err: err})
+		}
+	}
+	return failures
+}
+
+// define
+// Types the definition and adds it to the environment.
+// filename: Name of the file that contains the definition.
+// source: The definition.
+func (loader *Loader) define(filename string, source parser.Source) error {
+	var judgement, err = parser.ParseWith(source.Text, parser.Options{Line: source.Line, TypeOptional: true})
+	if err != nil {
+		return err
+	}
+	var derivation, checks, typeErr = TypeChecker.DefineJudgement(judgement, &loader.Environment)
+	if typeErr != nil {
+		return typeErr
+	}
+	if !checks {
+		return &Errors.TypeError{Message: "Does not type check, found " + derivation.Type.String(), Span: judgement.Type.Span}
+	}
+	loader.origins[judgement.Name] = origin{filename, source}
+	return nil
+}
+
+// Origin
+// Returns the file and the judgement the definition with the provided name was loaded from,
+// false if it was not loaded by this loader.
+// name: The name of a definition in Environment.
+func (loader *Loader) Origin(name *parsetree.Term) (string, parser.Source, bool) {
+	var found, ok = loader.origins[name]
+	return found.filename, found.source, ok
+}
+
+// unwrapPathError
+// Returns the reason of an error opening a file without repeating the path.
+func unwrapPathError(err error) string {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err.Error()
+	}
+	return err.Error()
+}
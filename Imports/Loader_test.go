@@ -0,0 +1,138 @@
+/*
+ * Parser and Lexical Analyser Loader_test.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package Imports
+
+import (
+	"Parser-TypeChecking/Parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles
+// Writes the files to a new temporary directory and returns the directory.
+// files: Contents of the files by their name relative to the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	var directory = t.TempDir()
+	for name, content := range files {
+		var path = filepath.Join(directory, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return directory
+}
+
+// TestLoad
+// Loads the imports of main.lam and compares the defined names and the failures.
+func TestLoad(t *testing.T) {
+	var tests = []struct {
+		name  string
+		files map[string]string
+		// Names of the definitions in the environment, in the order they were loaded.
+		defined []string
+		// Part of the message of every failure.
+		failures []string
+		// Amount of judgements of main.lam that are not imports.
+		judgements int
+	}{
+		{
+			name: "definitions of nested imports",
+			files: map[string]string{
+				"main.lam":        "import \"lib/church.lam\"\nid : A -> A\n",
+				"lib/church.lam":  "import \"prelude.lam\"\ndef true = \\t \\f t\ntrue : A -> B -> A\n",
+				"lib/prelude.lam": "def id = \\x x\n",
+			},
+			defined:    []string{"id", "true"},
+			judgements: 1,
+		},
+		{
+			name: "imported twice",
+			files: map[string]string{
+				"main.lam": "import \"a.lam\"\nimport \"b.lam\"\n",
+				"a.lam":    "import \"c.lam\"\ndef a = \\x x\n",
+				"b.lam":    "import \"./c.lam\"\ndef b = \\x x\n",
+				"c.lam":    "def c = \\x x\n",
+			},
+			defined: []string{"c", "a", "b"},
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"main.lam": "import \"a.lam\"\n",
+				"a.lam":    "import \"b.lam\"\ndef a = \\x x\n",
+				"b.lam":    "import \"a.lam\"\ndef b = \\x x\n",
+			},
+			defined:  []string{"b", "a"},
+			failures: []string{"Cycle in imports, a.lam imports b.lam imports a.lam"},
+		},
+		{
+			name:     "imports itself",
+			files:    map[string]string{"main.lam": "import \"main.lam\"\n"},
+			failures: []string{"Cycle in imports, main.lam imports main.lam"},
+		},
+		{
+			name:       "missing file",
+			files:      map[string]string{"main.lam": "import \"missing.lam\"\nx : A\n"},
+			failures:   []string{"Cannot read missing.lam"},
+			judgements: 1,
+		},
+		{
+			name:       "import below a judgement",
+			files:      map[string]string{"main.lam": "x : A\nimport \"a.lam\"\n", "a.lam": "def a = \\x x\n"},
+			failures:   []string{"Imports have to be at the top of the file"},
+			judgements: 1,
+		},
+		{
+			name:     "error in an imported definition",
+			files:    map[string]string{"main.lam": "import \"a.lam\"\n", "a.lam": "def a = \\x x x\n"},
+			failures: []string{"a.lam:1: Typecheck error: Cannot apply E1 to E2"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var directory = writeFiles(t, test.files)
+			// Relative names keep the messages independent of the temporary directory.
+			var previous, err = os.Getwd()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = os.Chdir(directory); err != nil {
+				t.Fatal(err)
+			}
+			defer os.Chdir(previous)
+			lines, err := ReadLines("main.lam")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var loader = NewLoader()
+			var judgements, failures = loader.Load("main.lam", parser.Sources(lines))
+			if len(judgements) != test.judgements {
+				t.Errorf("Load returned %d judgements, want %d", len(judgements), test.judgements)
+			}
+			var defined []string
+			for _, definition := range loader.Environment.Entries() {
+				defined = append(defined, definition.Name.Lexeme)
+			}
+			if strings.Join(defined, " ") != strings.Join(test.defined, " ") {
+				t.Errorf("Load defined %v, want %v", defined, test.defined)
+			}
+			if len(failures) != len(test.failures) {
+				t.Fatalf("Load failed with %v, want %v", failures, test.failures)
+			}
+			for i, failure := range failures {
+				if !strings.Contains(failure.Error(), test.failures[i]) {
+					t.Errorf("Load failed with %q, want %q", failure.Error(), test.failures[i])
+				}
+			}
+		})
+	}
+}
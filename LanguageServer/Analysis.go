@@ -0,0 +1,251 @@
+/*
+ * Parser and Lexical Analyser Analysis.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package LanguageServer
+
+import (
+	"Parser-TypeChecking/Errors"
+	"Parser-TypeChecking/Globals"
+	"Parser-TypeChecking/Parser"
+	"Parser-TypeChecking/Parsetree"
+	"Parser-TypeChecking/Tokens"
+	"Parser-TypeChecking/TypeChecker"
+	"Parser-TypeChecking/Types"
+	"errors"
+)
+
+// lineAnalysis
+// Result of parsing and type checking a single judgement of a document, which may span several lines.
+type lineAnalysis struct {
+	// The analysed judgement, its lines are separated by '\n'.
+	line []rune
+	// Zero based line number of the first line of the judgement in the document.
+	first int
+	// Rune offsets in line at which the lines of the judgement start.
+	starts []int
+	// Name of the definition, nil if the line is not a definition or could not be parsed.
+	name *parsetree.Term
+	// Definitions in scope of the line, those above it in the document.
+	environment *Globals.Environment
+	// Parsed context, nil if the line has none or could not be parsed.
+	context *parsetree.Term
+	// Parsed expression, nil if the line could not be parsed.
+	expression *parsetree.Term
+	// Derivation of the synthesized type, nil if the expression could not be typed.
+	derivation *TypeChecker.Derivation
+	// Diagnostics found on the line.
+	diagnostics []Diagnostic
+}
+
+// newAnalysis
+// Creates an analysis of the judgement without parsing it, its positions can be converted already.
+// source: The judgement.
+func newAnalysis(source parser.Source) *lineAnalysis {
+	var analysis = lineAnalysis{line: []rune(source.Text), first: source.Line - 1, starts: []int{0}}
+	for i, r := range analysis.line {
+		if r == '\n' {
+			analysis.starts = append(analysis.starts, i+1)
+		}
+	}
+	return &analysis
+}
+
+// analyseLine
+// Parses the judgement with optional type, types the expression and
+// checks the synthesized type against the given type.
+// source: The judgement to analyse.
+// environment: Definitions in scope of the judgement, a definition in source is added to it.
+func analyseLine(source parser.Source, environment *Globals.Environment) *lineAnalysis {
+	var analysis = newAnalysis(source)
+	analysis.environment = environment.Snapshot()
+	var judgement, err = parser.ParseWith(source.Text, parser.Options{Line: source.Line, TypeOptional: true})
+	if err != nil {
+		analysis.report(err)
+		return analysis
+	}
+	analysis.name = judgement.Name
+	analysis.context = judgement.Context
+	analysis.expression = judgement.Expression
+	if judgement.Name != nil {
+		var checks bool
+		analysis.derivation, checks, err = TypeChecker.DefineJudgement(judgement, environment)
+		if err != nil {
+			analysis.report(err)
+		} else if !checks {
+			analysis.report(&Errors.TypeError{
+				Message: "Does not type check, found " + analysis.derivation.Type.String(),
+				Span:    judgement.Type.Span,
+			})
+		}
+		return analysis
+	}
+	analysis.derivation, err = TypeChecker.SynthesizeJudgementIn(judgement, nil, environment)
+	if err != nil {
+		analysis.report(err)
+		return analysis
+	}
+	if judgement.Type == nil {
+		return analysis
+	}
+	expected, err := Types.FromTerm(judgement.Type)
+	if err != nil {
+		analysis.report(err)
+	} else if TypeChecker.NewInference().Unify(analysis.derivation.Type, expected) != nil {
+		analysis.report(&Errors.TypeError{
+			Message: "Does not type check, found " + analysis.derivation.Type.String(),
+			Span:    judgement.Type.Span,
+		})
+	}
+	return analysis
+}
+
+// report
+// Adds a diagnostic for err, the whole judgement is marked if err has no location.
+func (analysis *lineAnalysis) report(err error) {
+	var span = Tokens.Span{Start: 0, End: len(analysis.line)}
+	var located Errors.Located
+	if errors.As(err, &located) {
+		span = located.Location()
+	}
+	if span.End <= span.Start {
+		// Mark at least a single character, e.g. for a missing token at the end of the line.
+		span.End = span.Start + 1
+	}
+	analysis.diagnostics = append(analysis.diagnostics, Diagnostic{
+		Range:    analysis.toRange(span),
+		Severity: severityError,
+		Source:   "Parser-TypeChecking",
+		Message:  err.Error(),
+	})
+}
+
+// toRange
+// Converts a span in the judgement to a range in the document.
+func (analysis *lineAnalysis) toRange(span Tokens.Span) Range {
+	return Range{Start: analysis.toPosition(span.Start), End: analysis.toPosition(span.End)}
+}
+
+// toPosition
+// Converts a rune offset in the judgement to a position in the document.
+// offset: Rune offset in the judgement.
+func (analysis *lineAnalysis) toPosition(offset int) Position {
+	var index = 0
+	for index+1 < len(analysis.starts) && analysis.starts[index+1] <= offset {
+		index++
+	}
+	return Position{analysis.first + index, toCharacter(analysis.physicalLine(index), offset-analysis.starts[index])}
+}
+
+// toOffset
+// Converts a position in the document to a rune offset in the judgement, -1 if the position is outside of it.
+// position: Position in the document.
+func (analysis *lineAnalysis) toOffset(position Position) int {
+	var index = position.Line - analysis.first
+	if index < 0 || index >= len(analysis.starts) {
+		return -1
+	}
+	return analysis.starts[index] + toOffset(analysis.physicalLine(index), position.Character)
+}
+
+// physicalLine
+// Returns a line of the judgement without its line end.
+// index: Index of the line in the judgement, starting at 0.
+func (analysis *lineAnalysis) physicalLine(index int) []rune {
+	var end = len(analysis.line)
+	if index+1 < len(analysis.starts) {
+		end = analysis.starts[index+1] - 1
+	}
+	return analysis.line[analysis.starts[index]:end]
+}
+
+// contains
+// Returns whether the rune offset lies inside span.
+func contains(span Tokens.Span, offset int) bool {
+	return span.Start <= offset && offset < span.End
+}
+
+// typeAt
+// Returns the innermost typed subterm at the rune offset and its type.
+// For the variable of a lambda the annotated or inferred type of the variable is returned.
+// offset: Rune offset in the line.
+func (analysis *lineAnalysis) typeAt(offset int) (*parsetree.Term, *Types.Type) {
+	var derivation = analysis.derivation
+	if derivation == nil || !contains(derivation.Expression.Span, offset) {
+		return nil, nil
+	}
+	for {
+		var premise *TypeChecker.Derivation
+		for _, candidate := range derivation.Premises {
+			if contains(candidate.Expression.Span, offset) {
+				premise = candidate
+			}
+		}
+		if premise == nil {
+			break
+		}
+		derivation = premise
+	}
+	if derivation.Rule == TypeChecker.LamdbaRule {
+		var variable = derivation.Expression.Children[0]
+		if contains(variable.Span, offset) {
+			return variable, derivation.Type.Domain
+		}
+	}
+	return derivation.Expression, derivation.Type
+}
+
+// definitionAt
+// Returns the variable that binds the occurrence of a variable at the rune offset,
+// either the variable of a lambda, an assumption in the context or the name of a definition
+// above the line. Returns nil if there is none.
+// offset: Rune offset in the line.
+func (analysis *lineAnalysis) definitionAt(offset int) *parsetree.Term {
+	if analysis.expression == nil {
+		return nil
+	}
+	var occurrence, binders = findOccurrence(analysis.expression, offset, nil)
+	if occurrence == nil {
+		return nil
+	}
+	for i := len(binders) - 1; i >= 0; i-- {
+		if binders[i].Lexeme == occurrence.Lexeme {
+			return binders[i]
+		}
+	}
+	if analysis.context != nil {
+		for _, assumption := range analysis.context.Children {
+			if assumption.Lexeme == occurrence.Lexeme {
+				return assumption
+			}
+		}
+	}
+	if definition := analysis.environment.Lookup(occurrence.Lexeme); definition != nil {
+		return definition.Name
+	}
+	return nil
+}
+
+// findOccurrence
+// Searches the variable occurrence at the rune offset in expression.
+// Returns the occurrence and the variables of the lambdas around it, innermost last.
+// binders: Variables of the lambdas around expression.
+func findOccurrence(expression *parsetree.Term, offset int, binders []*parsetree.Term) (*parsetree.Term, []*parsetree.Term) {
+	if !contains(expression.Span, offset) {
+		return nil, nil
+	}
+	switch expression.Token {
+	case Tokens.TokenVariable:
+		return expression, binders
+	case Tokens.TokenLambda:
+		return findOccurrence(expression.Children[1], offset, append(binders, expression.Children[0]))
+	case Tokens.Application:
+		for _, child := range expression.Children {
+			if occurrence, scope := findOccurrence(child, offset, binders); occurrence != nil {
+				return occurrence, scope
+			}
+		}
+	}
+	return nil, nil
+}
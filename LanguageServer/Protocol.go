@@ -0,0 +1,219 @@
+/*
+ * Parser and Lexical Analyser Protocol.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+/*
+ * Messages of the Language Server Protocol that are used by the server,
+ * and the JSON-RPC framing with Content-Length headers.
+ */
+
+package LanguageServer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"unicode/utf16"
+)
+
+// JSON-RPC error codes.
+const (
+	methodNotFound = -32601
+	invalidParams  = -32602
+)
+
+// Severity of a diagnostic that reports an error.
+const severityError = 1
+
+// request
+// Incoming request or notification, notifications have no ID.
+type request struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+// response
+// Successful answer to a request.
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result"`
+}
+
+// errorResponse
+// Answer to a request that failed.
+type errorResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   responseError    `json:"error"`
+}
+
+// responseError
+// Describes why a request failed.
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// notification
+// Outgoing message that does not expect an answer.
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// Position
+// Zero based line and character offset, the offset counts UTF-16 code units.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range
+// Part of a document, End is exclusive.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location
+// Range inside a document.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// Diagnostic
+// Error or warning shown in the editor.
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+// publishDiagnosticsParams
+// Parameters of textDocument/publishDiagnostics.
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// markupContent
+// Text shown by the editor, Kind is plaintext or markdown.
+type markupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// hover
+// Result of textDocument/hover.
+type hover struct {
+	Contents markupContent `json:"contents"`
+	Range    Range         `json:"range"`
+}
+
+// textDocumentIdentifier
+// Identifies a document by its URI.
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// didOpenParams
+// Parameters of textDocument/didOpen.
+type didOpenParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+}
+
+// didChangeParams
+// Parameters of textDocument/didChange, the server asks for full document synchronisation.
+type didChangeParams struct {
+	TextDocument   textDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+// didCloseParams
+// Parameters of textDocument/didClose.
+type didCloseParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+// textDocumentPositionParams
+// Parameters of textDocument/hover and textDocument/definition.
+type textDocumentPositionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// readMessage
+// Reads the next message, a header with the Content-Length followed by the JSON body.
+// reader: The input stream of the client.
+func readMessage(reader *bufio.Reader) ([]byte, error) {
+	var header, err = textproto.NewReader(reader).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length header: %v", err)
+	}
+	var body = make([]byte, length)
+	if _, err = io.ReadFull(reader, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// writeMessage
+// Writes the message as JSON preceded by its Content-Length header.
+// writer: The output stream to the client.
+// message: The message to encode.
+func writeMessage(writer io.Writer, message interface{}) error {
+	var body, err = json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(writer, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = writer.Write(body)
+	return err
+}
+
+// toCharacter
+// Converts a rune offset in line to the amount of UTF-16 code units in front of it.
+// line: The line of the document.
+// offset: Rune offset, offsets after the end of the line are clamped.
+func toCharacter(line []rune, offset int) int {
+	if offset > len(line) {
+		offset = len(line)
+	}
+	return len(utf16.Encode(line[:offset]))
+}
+
+// toOffset
+// Converts an amount of UTF-16 code units to the rune offset in line.
+// line: The line of the document.
+// character: Offset in UTF-16 code units.
+func toOffset(line []rune, character int) int {
+	var units = 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len(line)
+}
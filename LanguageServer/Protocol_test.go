@@ -0,0 +1,91 @@
+/*
+ * Parser and Lexical Analyser Protocol_test.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package LanguageServer
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestPositions
+// Converts between rune offsets and UTF-16 code units, runes outside the Basic Multilingual Plane take two units.
+func TestPositions(t *testing.T) {
+	var tests = []struct {
+		line      string
+		offset    int
+		character int
+	}{
+		{`\x x : A`, 0, 0},
+		{`\x x : A`, 5, 5},
+		{`λx x : A`, 3, 3},
+		{`𝑥 : A`, 0, 0},
+		{`𝑥 : A`, 1, 2},
+		{`𝑥 : A`, 4, 5},
+		{`\𝑥^A 𝑥𝑦 : A`, 2, 3},
+		{`\𝑥^A 𝑥𝑦 : A`, 7, 10},
+		{`\𝑥^A 𝑥𝑦 : A`, 11, 14},
+	}
+	for _, test := range tests {
+		var line = []rune(test.line)
+		if got := toCharacter(line, test.offset); got != test.character {
+			t.Errorf("toCharacter(%q, %d) = %d, want %d", test.line, test.offset, got, test.character)
+		}
+		if got := toOffset(line, test.character); got != test.offset {
+			t.Errorf("toOffset(%q, %d) = %d, want %d", test.line, test.character, got, test.offset)
+		}
+	}
+}
+
+// TestPositionsOutOfRange
+// Positions after the end of the line and inside a surrogate pair are clamped.
+func TestPositionsOutOfRange(t *testing.T) {
+	var line = []rune(`𝑥𝑦`)
+	var tests = []struct {
+		name string
+		got  int
+		want int
+	}{
+		{"offset after the end", toCharacter(line, 10), 4},
+		{"character after the end", toOffset(line, 10), 2},
+		{"character inside the first pair", toOffset(line, 1), 1},
+		{"character inside the second pair", toOffset(line, 3), 2},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s: got %d, want %d", test.name, test.got, test.want)
+		}
+	}
+}
+
+// TestMessages
+// Messages written by writeMessage are read back by readMessage.
+func TestMessages(t *testing.T) {
+	var output bytes.Buffer
+	var messages = []interface{}{
+		Position{Line: 1, Character: 2},
+		map[string]string{"text": "λ𝑥 𝑥"},
+	}
+	for _, message := range messages {
+		if err := writeMessage(&output, message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var reader = bufio.NewReader(&output)
+	for _, want := range []string{`{"line":1,"character":2}`, `{"text":"λ𝑥 𝑥"}`} {
+		var body, err = readMessage(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != want {
+			t.Errorf("readMessage = %s, want %s", body, want)
+		}
+	}
+	if _, err := readMessage(bufio.NewReader(strings.NewReader("Content-Length: x\r\n\r\n"))); err == nil {
+		t.Error("readMessage accepted an invalid Content-Length")
+	}
+}
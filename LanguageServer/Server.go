@@ -0,0 +1,267 @@
+/*
+ * Parser and Lexical Analyser Server.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+/*
+ * Language server for files with a judgement on every line, communicates over JSON-RPC.
+ * Supports diagnostics, hover with the type of the subterm under the cursor and
+ * go to definition from a variable to the lambda, assumption or definition that binds it,
+ * a definition may be in an imported file.
+ */
+
+package LanguageServer
+
+import (
+	"Parser-TypeChecking/Errors"
+	"Parser-TypeChecking/Imports"
+	"Parser-TypeChecking/Parser"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// Server
+// State of the language server, the documents are kept in memory while they are open.
+type Server struct {
+	reader *bufio.Reader
+	writer io.Writer
+	// Analysis of every line of the open documents, by URI.
+	documents map[string][]*lineAnalysis
+	// Loader of the imports of the open documents, by URI.
+	loaders map[string]*Imports.Loader
+	// Set by the shutdown request, the server only exits afterwards.
+	shutdown bool
+}
+
+// NewServer
+// Creates a server that reads requests from input and writes responses to output.
+// input: Input stream of the client, e.g. os.Stdin.
+// output: Output stream to the client, e.g. os.Stdout.
+func NewServer(input io.Reader, output io.Writer) *Server {
+	return &Server{reader: bufio.NewReader(input), writer: output, documents: map[string][]*lineAnalysis{},
+		loaders: map[string]*Imports.Loader{}}
+}
+
+// Serve
+// Handles messages until the client sends exit or closes the input stream.
+// Returns an error if the server stopped without a shutdown request.
+func (server *Server) Serve() error {
+	for {
+		var body, err = readMessage(server.reader)
+		if err != nil {
+			if err == io.EOF && server.shutdown {
+				return nil
+			}
+			return err
+		}
+		var message request
+		if err = json.Unmarshal(body, &message); err != nil {
+			return err
+		}
+		if message.Method == "exit" {
+			if !server.shutdown {
+				return errors.New("exit without shutdown request")
+			}
+			return nil
+		}
+		if err = server.handle(&message); err != nil {
+			return err
+		}
+	}
+}
+
+// handle
+// Dispatches a single request or notification.
+func (server *Server) handle(message *request) error {
+	var result interface{}
+	var err error
+	switch message.Method {
+	case "initialize":
+		result = map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				// Full synchronisation, every change sends the whole document.
+				"textDocumentSync":   1,
+				"hoverProvider":      true,
+				"definitionProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "Parser-TypeChecking"},
+		}
+	case "shutdown":
+		server.shutdown = true
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err = json.Unmarshal(message.Params, &params); err == nil {
+			return server.update(params.TextDocument.URI, params.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err = json.Unmarshal(message.Params, &params); err == nil && len(params.ContentChanges) > 0 {
+			return server.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+		}
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err = json.Unmarshal(message.Params, &params); err == nil {
+			delete(server.documents, params.TextDocument.URI)
+			delete(server.loaders, params.TextDocument.URI)
+			return server.notify("textDocument/publishDiagnostics",
+				publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+		}
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(message.Params, &params); err == nil {
+			result = server.hover(&params)
+		}
+	case "textDocument/definition":
+		var params textDocumentPositionParams
+		if err = json.Unmarshal(message.Params, &params); err == nil {
+			result = server.definition(&params)
+		}
+	default:
+		if message.ID == nil {
+			// Unknown notifications, e.g. initialized, are ignored.
+			return nil
+		}
+		return server.fail(message.ID, methodNotFound, "unknown method "+message.Method)
+	}
+	if err != nil {
+		if message.ID == nil {
+			return nil
+		}
+		return server.fail(message.ID, invalidParams, err.Error())
+	}
+	if message.ID == nil {
+		return nil
+	}
+	return writeMessage(server.writer, response{JSONRPC: "2.0", ID: message.ID, Result: result})
+}
+
+// fail
+// Answers the request with an error.
+func (server *Server) fail(id *json.RawMessage, code int, text string) error {
+	return writeMessage(server.writer, errorResponse{JSONRPC: "2.0", ID: id, Error: responseError{code, text}})
+}
+
+// notify
+// Sends a notification to the client.
+func (server *Server) notify(method string, params interface{}) error {
+	return writeMessage(server.writer, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// update
+// Analyses every judgement of the new text of the document and publishes the diagnostics.
+// uri: Identifies the document.
+// text: The complete content of the document.
+func (server *Server) update(uri string, text string) error {
+	var lines = strings.Split(text, "\n")
+	for number, line := range lines {
+		lines[number] = strings.TrimSuffix(line, "\r")
+	}
+	// Every line refers to the analysis of the judgement it is part of, nil for blank lines.
+	var analyses = make([]*lineAnalysis, len(lines))
+	var diagnostics = []Diagnostic{}
+	// Imports are resolved relative to the document, an error in an imported file is reported at the import.
+	var path = filename(uri)
+	var loader = Imports.NewLoader()
+	var sources, failures = loader.Load(path, parser.Sources(lines))
+	for _, failure := range failures {
+		var analysis *lineAnalysis
+		if failure.Filename == path {
+			analysis = newAnalysis(failure.Source)
+			analysis.report(failure.Err)
+		} else {
+			analysis = newAnalysis(failure.Import)
+			var _, span, _ = parser.ImportPath(failure.Import)
+			analysis.report(&Errors.ImportError{Message: failure.Error(), Span: span})
+		}
+		diagnostics = append(diagnostics, analysis.diagnostics...)
+	}
+	for _, source := range sources {
+		var analysis = analyseLine(source, &loader.Environment)
+		for i := range analysis.starts {
+			analyses[analysis.first+i] = analysis
+		}
+		diagnostics = append(diagnostics, analysis.diagnostics...)
+	}
+	server.documents[uri] = analyses
+	server.loaders[uri] = loader
+	return server.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+// lineAt
+// Returns the analysis of the judgement at position and the rune offset of position in the judgement,
+// nil if the document is not open or there is no judgement at position.
+func (server *Server) lineAt(params *textDocumentPositionParams) (*lineAnalysis, int) {
+	var analyses = server.documents[params.TextDocument.URI]
+	if params.Position.Line < 0 || params.Position.Line >= len(analyses) || analyses[params.Position.Line] == nil {
+		return nil, 0
+	}
+	var analysis = analyses[params.Position.Line]
+	return analysis, analysis.toOffset(params.Position)
+}
+
+// hover
+// Returns the type of the subterm under the cursor, nil if there is none.
+func (server *Server) hover(params *textDocumentPositionParams) *hover {
+	var analysis, offset = server.lineAt(params)
+	if analysis == nil {
+		return nil
+	}
+	var term, found = analysis.typeAt(offset)
+	if term == nil {
+		return nil
+	}
+	return &hover{
+		Contents: markupContent{Kind: "markdown", Value: "```\n" + term.String() + " : " + found.String() + "\n```"},
+		Range:    analysis.toRange(term.Span),
+	}
+}
+
+// definition
+// Returns the location of the variable that binds the variable under the cursor, nil if there is none.
+func (server *Server) definition(params *textDocumentPositionParams) *Location {
+	var analysis, offset = server.lineAt(params)
+	if analysis == nil {
+		return nil
+	}
+	var binder = analysis.definitionAt(offset)
+	if binder == nil {
+		return nil
+	}
+	// The name of a definition is on the line of that definition, which may be in an imported file.
+	var uri = params.TextDocument.URI
+	if imported, source, ok := server.loaders[uri].Origin(binder); ok {
+		uri, analysis = fileURI(imported), newAnalysis(source)
+	}
+	for _, owner := range server.documents[params.TextDocument.URI] {
+		if owner != nil && owner.name == binder {
+			analysis = owner
+		}
+	}
+	var span = binder.Span
+	span.End = span.Start + len([]rune(binder.Lexeme))
+	return &Location{URI: uri, Range: analysis.toRange(span)}
+}
+
+// filename
+// Returns the path of the file identified by uri, or uri itself if it is not a file URI.
+func filename(uri string) string {
+	if parsed, err := url.Parse(uri); err == nil && parsed.Scheme == "file" {
+		return filepath.FromSlash(parsed.Path)
+	}
+	return uri
+}
+
+// fileURI
+// Returns the URI that identifies the file, the inverse of filename.
+// path: Name of the file.
+func fileURI(path string) string {
+	if absolute, err := filepath.Abs(path); err == nil {
+		path = absolute
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
@@ -7,131 +7,343 @@ package LexicalAnalyser
 
 import (
 	"Parser-TypeChecking/Constants"
-	"Parser-TypeChecking/Globals"
+	"Parser-TypeChecking/Errors"
 	"Parser-TypeChecking/Tokens"
 	"fmt"
-	"os"
+	"strings"
 	"unicode"
 )
 
-// AddChar
-// Adds the provided character to the lexeme.
-func AddChar(context *Globals.Vars) {
-	context.Lexeme = append(context.Lexeme, context.ReadChar)
+// Keyword that starts a definition, it cannot be used as variable.
+const definitionKeyword = "def"
+
+// Keyword that starts an import of another file, e.g. import "prelude.lam", it cannot be used as variable.
+const importKeyword = "import"
+
+// Lexer
+// Turns a line into a stream of tokens. Tokens are analysed on demand and kept in a buffer,
+// hence the parser can look ahead an arbitrary amount of tokens without analysing the line again. Comments, starting with "--" or '#', are skipped.
+// A judgement can span several lines separated by '\n' if every line but the last ends with a '\',
+// the line numbers of the tokens are counted accordingly.
+type Lexer struct {
+	// The line that is analysed.
+	line []rune
+	// Line number in the input file of the character that was read last, 0 if not read from a file.
+	lineNumber int
+	// Index on line of the character that was read last.
+	index int
+	// Character that was read last.
+	readChar rune
+	// Character class of readChar.
+	charClass int
+	// Characters of the token that is currently analysed.
+	lexeme []rune
+	// All tokens analysed so far, the last one is an end of line or a syntax error once the whole line is analysed.
+	tokens []Tokens.Token
+	// Error of the last token in tokens if it is a syntax error, else nil.
+	err error
+	// Index in tokens of the token that Next returns.
+	position int
+}
+
+// NewLexer
+// Creates a Lexer that has not analysed any token of the line yet.
+// line: The line to analyse, possibly several lines separated by '\n'.
+// lineNumber: Line number of the (first) line in the input file, used in the spans of the tokens.
+func NewLexer(line []rune, lineNumber int) *Lexer {
+	return &Lexer{line: line, lineNumber: lineNumber, index: -1}
+}
+
+// Next
+// Returns the next token and moves past it. Once the end of the line or a syntax error is reached,
+// every further call returns that same token.
+func (lexer *Lexer) Next() (Tokens.Token, error) {
+	var token, err = lexer.Peek(0)
+	if lexer.position < len(lexer.tokens)-1 || !lexer.finished() {
+		lexer.position++
+	}
+	return token, err
+}
+
+// Peek
+// Returns the token n positions after the next token without moving past any token, Peek(0) is the next token.
+// Tokens after the end of the line or after a syntax error are that same end of line or syntax error.
+// n: Amount of tokens to look past.
+func (lexer *Lexer) Peek(n int) (Tokens.Token, error) {
+	for len(lexer.tokens) <= lexer.position+n && !lexer.finished() {
+		var token, err = lexer.analyse()
+		lexer.tokens = append(lexer.tokens, token)
+		lexer.err = err
+	}
+	var index = lexer.position + n
+	if index >= len(lexer.tokens)-1 {
+		// Only the last token can be a syntax error.
+		return lexer.tokens[len(lexer.tokens)-1], lexer.err
+	}
+	return lexer.tokens[index], nil
 }
 
-// GetChar
+// finished
+// Returns whether the whole line is analysed, or a syntax error stopped the analysis.
+func (lexer *Lexer) finished() bool {
+	if len(lexer.tokens) == 0 {
+		return false
+	}
+	var last = lexer.tokens[len(lexer.tokens)-1].Kind
+	return last == Tokens.LexicalEndOfLine || last == Tokens.SyntaxError
+}
+
+// addChar
+// Adds the character that was read last to the lexeme.
+func (lexer *Lexer) addChar() {
+	lexer.lexeme = append(lexer.lexeme, lexer.readChar)
+}
+
+// getChar
 // Gets the next character in our currently analysed line and determines its character class.
-// context: Contains the whole expression.
-func GetChar(context *Globals.Vars) {
-	context.Index++
-	if context.Index >= len(context.CurrentLine) {
-		context.CharClass = CharClass.ENDOFLINE
+func (lexer *Lexer) getChar() {
+	lexer.index++
+	if lexer.index >= len(lexer.line) {
+		lexer.charClass = CharClass.ENDOFLINE
 	} else {
-		context.ReadChar = context.CurrentLine[context.Index]
-		if unicode.IsDigit(context.ReadChar) {
-			context.CharClass = CharClass.DIGIT
-		} else if unicode.IsLetter(context.ReadChar) && context.ReadChar != 'λ' {
-			if unicode.IsLower(context.ReadChar) {
-				context.CharClass = CharClass.LOWLETTER
+		lexer.readChar = lexer.line[lexer.index]
+		if unicode.IsDigit(lexer.readChar) {
+			lexer.charClass = CharClass.DIGIT
+		} else if unicode.IsLetter(lexer.readChar) && lexer.readChar != 'λ' {
+			if unicode.IsLower(lexer.readChar) {
+				lexer.charClass = CharClass.LOWLETTER
 			} else {
-				context.CharClass = CharClass.UPLETTER
+				lexer.charClass = CharClass.UPLETTER
 			}
-		} else if (context.ReadChar == '\\') || (context.ReadChar == 'λ') {
-			context.CharClass = CharClass.LAMBDA
-		} else if context.ReadChar == '(' {
-			context.CharClass = CharClass.LBRACKET
-		} else if context.ReadChar == ')' {
-			context.CharClass = CharClass.RBRACKET
-		} else if context.ReadChar == '^' {
-			context.CharClass = CharClass.TYPESYMBOL
-		} else if context.ReadChar == '-' {
-			context.CharClass = CharClass.FUNCTION1
-		} else if context.ReadChar == '>' {
-			context.CharClass = CharClass.FUNCTION2
-		} else if context.ReadChar == ' ' {
-			context.CharClass = CharClass.SPACE
-		} else if context.ReadChar == ':' {
-			context.CharClass = CharClass.DoubleDot
+		} else if (lexer.readChar == '\\') || (lexer.readChar == 'λ') {
+			lexer.charClass = CharClass.LAMBDA
+		} else if lexer.readChar == '(' {
+			lexer.charClass = CharClass.LBRACKET
+		} else if lexer.readChar == ')' {
+			lexer.charClass = CharClass.RBRACKET
+		} else if lexer.readChar == '^' {
+			lexer.charClass = CharClass.TYPESYMBOL
+		} else if lexer.readChar == '-' {
+			lexer.charClass = CharClass.FUNCTION1
+		} else if lexer.readChar == '>' {
+			lexer.charClass = CharClass.FUNCTION2
+		} else if lexer.readChar == ' ' || lexer.readChar == '\t' || lexer.readChar == '\r' {
+			lexer.charClass = CharClass.SPACE
+		} else if lexer.readChar == '\n' {
+			lexer.charClass = CharClass.NEWLINE
+		} else if lexer.readChar == '#' {
+			lexer.charClass = CharClass.COMMENT
+		} else if lexer.readChar == '=' {
+			lexer.charClass = CharClass.EQUALS
+		} else if lexer.readChar == ':' {
+			lexer.charClass = CharClass.DoubleDot
+		} else if lexer.readChar == ',' {
+			lexer.charClass = CharClass.COMMA
+		} else if lexer.readChar == '|' {
+			lexer.charClass = CharClass.TURNSTILE1
+		} else if lexer.readChar == '⊢' {
+			lexer.charClass = CharClass.TURNSTILE
+		} else if lexer.readChar == '→' {
+			lexer.charClass = CharClass.ARROW
 		} else {
-			context.CharClass = CharClass.UNDEFINED
+			lexer.charClass = CharClass.UNDEFINED
 		}
 	}
 }
 
-// LexicalAnalyser
-// Analyses the variables.CurrentLine until the next token is found.
-// returns the token that is found.
-// now also works for typetree.
-// context: Contains the whole expression.
-func LexicalAnalyser(context *Globals.Vars) int {
-	if context.DebugMode {
-		fmt.Println("   Lexical called")
+// analyse
+// Analyses the line until the next token is found.
+// returns the token that is found, or a SyntaxError if the characters do not form a token.
+func (lexer *Lexer) analyse() (Tokens.Token, error) {
+	lexer.getChar()
+	if err := lexer.skipSeparators(); err != nil {
+		return Tokens.Token{Kind: Tokens.SyntaxError, Span: lexer.span(lexer.index)}, err
 	}
-	GetChar(context)
-	// Save the previous token.
-	context.PrevToken = context.Token
-	if context.CharClass != CharClass.UNDEFINED {
-		switch context.CharClass {
+	// The token starts at the character that was just read.
+	var start = lexer.index
+	lexer.lexeme = nil
+	var kind, err = lexer.analyseToken()
+	return Tokens.Token{Kind: kind, Lexeme: string(lexer.lexeme), Span: lexer.span(start)}, err
+}
+
+// skipSeparators
+// Skips spaces, comments and continued line ends in front of the next token.
+// returns a SyntaxError if a line continuation is not followed by another line.
+func (lexer *Lexer) skipSeparators() error {
+	for {
+		switch {
+		case lexer.charClass == CharClass.SPACE:
+			// Spaces only separate tokens.
+		case lexer.charClass == CharClass.NEWLINE:
+			lexer.lineNumber++
+		case lexer.charClass == CharClass.COMMENT || lexer.charClass == CharClass.FUNCTION1 && lexer.peekChar() == '-':
+			// A comment lasts until the end of the line.
+			for lexer.peekChar() != '\n' && lexer.index+1 < len(lexer.line) {
+				lexer.index++
+			}
+		case lexer.readChar == '\\' && lexer.charClass == CharClass.LAMBDA && lexer.continued():
+			// The judgement continues on the next line, which has to exist.
+			if !strings.ContainsRune(string(lexer.line[lexer.index:]), '\n') {
+				return lexer.lexicalError(Errors.InvalidContinuation, "Continuation at end of input, expected another line after '\\'.")
+			}
+		default:
+			return nil
+		}
+		lexer.getChar()
+	}
+}
+
+// peekChar
+// Returns the character after the character that was read last, 0 at the end of the line.
+func (lexer *Lexer) peekChar() rune {
+	if lexer.index+1 >= len(lexer.line) {
+		return 0
+	}
+	return lexer.line[lexer.index+1]
+}
+
+// continued
+// Returns whether the '\' that was read last is a line continuation: only spaces or a comment follow it on its line.
+func (lexer *Lexer) continued() bool {
+	for i := lexer.index + 1; i < len(lexer.line); i++ {
+		switch lexer.line[i] {
+		case ' ', '\t', '\r':
+			continue
+		case '\n', '#':
+			return true
+		case '-':
+			return i+1 < len(lexer.line) && lexer.line[i+1] == '-'
+		}
+		return false
+	}
+	return true
+}
+
+// analyseToken
+// Determines the token that starts at the character that was just read, the index is left at its last character.
+func (lexer *Lexer) analyseToken() (int, error) {
+	if lexer.charClass != CharClass.UNDEFINED {
+		switch lexer.charClass {
 		case CharClass.LOWLETTER:
 			// loop through until the variable is complete.
-			for context.CharClass == CharClass.LOWLETTER ||
-				context.CharClass == CharClass.UPLETTER || context.CharClass == CharClass.DIGIT {
-				AddChar(context)
-				GetChar(context)
+			for lexer.charClass == CharClass.LOWLETTER ||
+				lexer.charClass == CharClass.UPLETTER || lexer.charClass == CharClass.DIGIT {
+				lexer.addChar()
+				lexer.getChar()
 			}
 			// Do not lose the last read character, this will be handled on the next call.
-			context.Index--
-			return Tokens.TokenVariable
+			lexer.index--
+			switch string(lexer.lexeme) {
+			case definitionKeyword:
+				return Tokens.TokenDefinition, nil
+			case importKeyword:
+				return Tokens.TokenImport, nil
+			}
+			return Tokens.TokenVariable, nil
 		case CharClass.UPLETTER:
 			// loop through until the variable is complete
-			for context.CharClass == CharClass.LOWLETTER ||
-				context.CharClass == CharClass.UPLETTER || context.CharClass == CharClass.DIGIT {
-				AddChar(context)
-				GetChar(context)
+			for lexer.charClass == CharClass.LOWLETTER ||
+				lexer.charClass == CharClass.UPLETTER || lexer.charClass == CharClass.DIGIT {
+				lexer.addChar()
+				lexer.getChar()
 			}
 			// do not lose the last read character, this will be handled on the next call.
-			context.Index--
-			return Tokens.TokenUVar
+			lexer.index--
+			return Tokens.TokenUVar, nil
 		case CharClass.TYPESYMBOL: // ^
-			// todo implement this right
-			return Tokens.TypeSymbol
+			lexer.addChar()
+			return Tokens.TypeSymbol, nil
 		case CharClass.FUNCTION1: // -
-			// todo implement this right
-			GetChar(context) //get the function 2
-			return Tokens.TokenFunction
+			// The '-' has to be directly followed by a '>'.
+			var start = lexer.index
+			lexer.addChar()
+			lexer.getChar()
+			if lexer.charClass != CharClass.FUNCTION2 {
+				return Tokens.SyntaxError, lexer.operatorError(start, Errors.InvalidFunction, "Expected > after - in function type (->)")
+			}
+			lexer.addChar()
+			return Tokens.TokenFunction, nil
+		case CharClass.ARROW: // →
+			lexer.addChar()
+			return Tokens.TokenFunction, nil
 		case CharClass.FUNCTION2: // >
-			fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: Function type had invalid declaration")
-			os.Exit(1)
+			return Tokens.SyntaxError, lexer.lexicalError(Errors.InvalidFunction, "Expected - in front of > in function type (->).")
 		case CharClass.DIGIT:
-			fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: Cannot start a variable with digit")
-			os.Exit(1)
+			return Tokens.SyntaxError, lexer.lexicalError(Errors.InvalidVariable, "Cannot start a variable with digit")
 		case CharClass.LBRACKET:
-			return Tokens.TokenLeftBracket
+			lexer.addChar()
+			return Tokens.TokenLeftBracket, nil
 		case CharClass.RBRACKET:
-			return Tokens.TokenRightBracket
+			lexer.addChar()
+			return Tokens.TokenRightBracket, nil
 		case CharClass.LAMBDA:
-			return Tokens.TokenLambda
-		case CharClass.SPACE:
-			return LexicalAnalyser(context)
+			lexer.addChar()
+			return Tokens.TokenLambda, nil
 		case CharClass.DoubleDot:
-			return Tokens.TokenDoubleDot
+			lexer.addChar()
+			return Tokens.TokenDoubleDot, nil
+		case CharClass.COMMA:
+			lexer.addChar()
+			return Tokens.TokenComma, nil
+		case CharClass.EQUALS:
+			lexer.addChar()
+			return Tokens.TokenEquals, nil
+		case CharClass.TURNSTILE:
+			lexer.addChar()
+			return Tokens.TokenTurnstile, nil
+		case CharClass.TURNSTILE1: // |
+			// The '|' has to be directly followed by a '-'.
+			var start = lexer.index
+			lexer.addChar()
+			lexer.getChar()
+			if lexer.charClass != CharClass.FUNCTION1 {
+				return Tokens.SyntaxError, lexer.operatorError(start, Errors.InvalidTurnstile, "Expected - after | in turnstile (|-)")
+			}
+			lexer.addChar()
+			return Tokens.TokenTurnstile, nil
 		case CharClass.ENDOFLINE:
-			return Tokens.LexicalEndOfLine
+			return Tokens.LexicalEndOfLine, nil
 		}
 	}
-	return Tokens.SyntaxError
+	return Tokens.SyntaxError, lexer.lexicalError(Errors.UnknownCharacter, fmt.Sprintf("unknown character %q.", lexer.readChar))
+}
+
+// lexicalError
+// Creates a SyntaxError for the character that was just read.
+// kind: The kind of syntax error.
+// message: Description of the error.
+func (lexer *Lexer) lexicalError(kind int, message string) error {
+	return Errors.NewSyntaxError(kind, message, lexer.line,
+		Tokens.Span{Line: lexer.lineNumber, Start: lexer.index, End: lexer.index + 1})
+}
+
+// operatorError
+// Creates a SyntaxError for an operator of two characters of which the second character is wrong.
+// The error spans from the first character of the operator up to and including the wrong character.
+// start: Index of the first character of the operator.
+// kind: The kind of syntax error.
+// message: Description of the error, the wrong character is added to it.
+func (lexer *Lexer) operatorError(start int, kind int, message string) error {
+	var found = "end of line"
+	if lexer.charClass != CharClass.ENDOFLINE {
+		found = fmt.Sprintf("%q", lexer.readChar)
+	}
+	return Errors.NewSyntaxError(kind, message+", found "+found+".", lexer.line, lexer.span(start))
 }
 
-// GoBackToLastToken
-// Reverts the last read token, and resets the LexicalAnalyser to read the same token on
-// the next function call.
-// context: Contains the whole expression.
-func GoBackToLastToken(context *Globals.Vars) {
-	context.Index--
-	// Go back to previous token.
-	for context.CurrentLine[context.Index] == ' ' {
-		context.Index--
+// span
+// Returns the span of the token that starts at start and ends at the last read character.
+// start: Index of the first character of the token.
+func (lexer *Lexer) span(start int) Tokens.Span {
+	var end = lexer.index + 1
+	if start > len(lexer.line) {
+		start = len(lexer.line)
+	}
+	if end > len(lexer.line) {
+		end = len(lexer.line)
+	}
+	if end < start {
+		end = start
 	}
-	context.Lexeme = nil
+	return Tokens.Span{Line: lexer.lineNumber, Start: start, End: end}
 }
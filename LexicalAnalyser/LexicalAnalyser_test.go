@@ -0,0 +1,138 @@
+/*
+ * Parser and Lexical Analyser LexicalAnalyser_test.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package LexicalAnalyser
+
+import (
+	"Parser-TypeChecking/Errors"
+	"Parser-TypeChecking/Tokens"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// lexemes
+// Returns the names and lexemes of all tokens of the line up to the end of line, e.g. Variable(x) Lambda(\),
+// and the syntax error that stopped the analysis.
+// line: The line to analyse.
+func lexemes(line string) (string, error) {
+	var lexer = NewLexer([]rune(line), 1)
+	var found []string
+	for {
+		var token, err = lexer.Next()
+		if err != nil || token.Kind == Tokens.LexicalEndOfLine {
+			return strings.Join(found, " "), err
+		}
+		found = append(found, Tokens.Names[token.Kind]+"("+token.Lexeme+")")
+	}
+}
+
+// TestOperators
+// Lexes the operators, including their alternative spellings.
+func TestOperators(t *testing.T) {
+	var tests = []struct {
+		line string
+		want string
+	}{
+		{`\x^A x`, `Lambda(\) Variable(x) TypeSymbol(^) UVar(A) Variable(x)`},
+		{`λx x`, `Lambda(λ) Variable(x) Variable(x)`},
+		{`A->B`, `UVar(A) Function(->) UVar(B)`},
+		{`A → B`, `UVar(A) Function(→) UVar(B)`},
+		{`x:A, y:B |- x`, `Variable(x) DoubleDot(:) UVar(A) Comma(,) Variable(y) DoubleDot(:) UVar(B) Turnstile(|-) Variable(x)`},
+		{`x:A ⊢ x`, `Variable(x) DoubleDot(:) UVar(A) Turnstile(⊢) Variable(x)`},
+		{`(x1 yZ)`, `LeftBracket(() Variable(x1) Variable(yZ) RightBracket())`},
+		{`def id = \x x`, `Definition(def) Variable(id) Equals(=) Lambda(\) Variable(x) Variable(x)`},
+		{`import x`, `Import(import) Variable(x)`},
+		{`define imports`, `Variable(define) Variable(imports)`},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			var got, err = lexemes(test.line)
+			if err != nil {
+				t.Fatalf("lexing %q: %v", test.line, err)
+			}
+			if got != test.want {
+				t.Errorf("lexing %q = %s, want %s", test.line, got, test.want)
+			}
+		})
+	}
+}
+
+// TestComments
+// Comments, continued line ends and spaces separate tokens but are not tokens themselves.
+func TestComments(t *testing.T) {
+	var tests = []struct {
+		line string
+		want string
+	}{
+		{`x # comment`, `Variable(x)`},
+		{`x -- comment -> A`, `Variable(x)`},
+		{`# only a comment`, ``},
+		{"x \\\n  y", `Variable(x) Variable(y)`},
+		{"x \\ # comment\n  y", `Variable(x) Variable(y)`},
+		{"x \\ -- comment\n  y", `Variable(x) Variable(y)`},
+		{`\x -- y`, `Lambda(\) Variable(x)`},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			var got, err = lexemes(test.line)
+			if err != nil {
+				t.Fatalf("lexing %q: %v", test.line, err)
+			}
+			if got != test.want {
+				t.Errorf("lexing %q = %s, want %s", test.line, got, test.want)
+			}
+		})
+	}
+}
+
+// TestSyntaxErrors
+// Lines that cannot be lexed report the kind and the span of the malformed characters.
+func TestSyntaxErrors(t *testing.T) {
+	var tests = []struct {
+		line  string
+		kind  int
+		start int
+		end   int
+	}{
+		{`A - > B`, Errors.InvalidFunction, 2, 4},
+		{`A > B`, Errors.InvalidFunction, 2, 3},
+		{`x | - y`, Errors.InvalidTurnstile, 2, 4},
+		{`1x`, Errors.InvalidVariable, 0, 1},
+		{`x $ y`, Errors.UnknownCharacter, 2, 3},
+		{`x \`, Errors.InvalidContinuation, 2, 3},
+		{`x \ # comment`, Errors.InvalidContinuation, 2, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			var _, err = lexemes(test.line)
+			var syntaxError *Errors.SyntaxError
+			if !errors.As(err, &syntaxError) {
+				t.Fatalf("lexing %q = %v, want a syntax error", test.line, err)
+			}
+			if syntaxError.Kind != test.kind {
+				t.Errorf("lexing %q: kind %d, want %d", test.line, syntaxError.Kind, test.kind)
+			}
+			if span := syntaxError.Location(); span.Start != test.start || span.End != test.end {
+				t.Errorf("lexing %q: span %d-%d, want %d-%d", test.line, span.Start, span.End, test.start, test.end)
+			}
+		})
+	}
+}
+
+// TestLineNumbers
+// Tokens on continued lines get the line number of the line they are on.
+func TestLineNumbers(t *testing.T) {
+	var lexer = NewLexer([]rune("x \\\n y \\\n\n z"), 3)
+	for _, want := range []int{3, 4, 6} {
+		var token, err = lexer.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token.Span.Line != want {
+			t.Errorf("%s is on line %d, want %d", token.Lexeme, token.Span.Line, want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+/*
+ * Parser and Lexical Analyser Expect.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package parser
+
+import "strings"
+
+// Marks the start of an expected-verdict annotation in a comment.
+const expectKeyword = "expect:"
+
+// Expectation
+// Returns the verdict of an expected-verdict annotation such as "-- expect: ok" in a comment of the judgement,
+// or an empty string if the judgement has none. The annotation is a comment, hence it is ignored by the parser.
+// text: The judgement, possibly spanning several lines.
+func Expectation(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		var index = commentStart(line)
+		if index < 0 {
+			continue
+		}
+		var comment = strings.TrimSpace(strings.TrimLeft(line[index:], "-#"))
+		if strings.HasPrefix(comment, expectKeyword) {
+			return strings.TrimSpace(strings.TrimPrefix(comment, expectKeyword))
+		}
+	}
+	return ""
+} // Expectation
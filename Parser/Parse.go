@@ -0,0 +1,61 @@
+/*
+ * Parser and Lexical Analyser Parse.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package parser
+
+import (
+	"Parser-TypeChecking/Globals"
+	"Parser-TypeChecking/LexicalAnalyser"
+	"Parser-TypeChecking/Parsetree"
+)
+
+// Options
+// Settings for ParseWith, the zero value parses a judgement with a required type.
+type Options struct {
+	// Line number of the input in its file, used in the spans of errors.
+	Line int
+	// Whether the ':' and TypeExpression may be omitted, e.g. to synthesize the type.
+	TypeOptional bool
+	// Whether the steps of the parser are printed.
+	Debug bool
+}
+
+// Parse
+// Parses the input as a judgement with a required type.
+// Every call uses its own parser state, hence Parse can be called from multiple goroutines.
+// input: The line to parse.
+func Parse(input string) (*parsetree.Judgement, error) {
+	return ParseWith(input, Options{})
+} // Parse
+
+// ParseWith
+// Parses the input as a judgement according to the options.
+// Every call uses its own parser state, hence ParseWith can be called from multiple goroutines.
+// input: The line to parse.
+// options: Settings of the parser.
+func ParseWith(input string, options Options) (*parsetree.Judgement, error) {
+	var context = new(Globals.Vars)
+	context.LineNumber = options.Line
+	context.CurrentLine = []rune(input)
+	context.DebugMode = options.Debug
+	context.Lexer = LexicalAnalyser.NewLexer(context.CurrentLine, options.Line)
+	context.Tree.IndexDoubleDot = -1
+	var err = NextToken(context)
+	if err == nil {
+		err = judgement(context, !options.TypeOptional)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &parsetree.Judgement{
+		Line:       options.Line,
+		Source:     context.CurrentLine,
+		Name:       context.Tree.Name,
+		Context:    context.Tree.Context,
+		Expression: context.Tree.Expression,
+		Type:       context.Tree.Type,
+		Tree:       context.Tree,
+	}, nil
+} // ParseWith
@@ -0,0 +1,67 @@
+/*
+ * Parser and Lexical Analyser Parse_test.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package parser
+
+import (
+	"testing"
+)
+
+// TestParse
+// Parses judgements and prints the parts of the parse tree.
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		input      string
+		expression string
+		typ        string
+	}{
+		{`\x^A x : A -> A`, `\x^A x`, `A -> A`},
+		{`x y z : A`, `x y z`, `A`},
+		{`x (y z) : (A -> B) -> C`, `x (y z)`, `(A -> B) -> C`},
+		{`λf^(A→B) λx^A f x : (A→B)→A→B`, `\f^(A -> B) \x^A f x`, `(A -> B) -> A -> B`},
+		{"\\x \\\n  x : A -> A", `\x x`, `A -> A`},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			var judgement, err = Parse(test.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) = %v", test.input, err)
+			}
+			if got := judgement.Expression.String(); got != test.expression {
+				t.Errorf("Parse(%q) has expression %s, want %s", test.input, got, test.expression)
+			}
+			if got := judgement.Type.String(); got != test.typ {
+				t.Errorf("Parse(%q) has type %s, want %s", test.input, got, test.typ)
+			}
+		})
+	}
+}
+
+// TestParseErrors
+// Lines that do not follow the grammar are rejected with a message that names what was found.
+func TestParseErrors(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{`: A`, "Expected an expression but got ':'."},
+		{`-> A`, "Expected an expression but got '->'."},
+		{`^A`, "Expected an expression but got '^', it can only follow the variable of a lambda."},
+		{`x`, "Expected Judgement Function (:)."},
+		{`\x import : A`, "import can only start a line, it cannot be used as variable."},
+		{`(x : A`, "Expected Closing Bracket."},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			var _, err = Parse(test.input)
+			if err == nil {
+				t.Fatalf("Parse(%q) succeeded, want %q", test.input, test.want)
+			}
+			if got := err.Error(); got != "Syntax error: "+test.want {
+				t.Errorf("Parse(%q) = %q, want %q", test.input, got, test.want)
+			}
+		})
+	}
+}
@@ -6,261 +6,465 @@
 package parser
 
 import (
+	"Parser-TypeChecking/Errors"
 	"Parser-TypeChecking/Globals"
 	"Parser-TypeChecking/LexicalAnalyser"
+	"Parser-TypeChecking/Parsetree"
 	"Parser-TypeChecking/Tokens"
 	"fmt"
-	"os"
 )
 
-// Judgement Initiates Recursive Descent Parsing.
-// Expects a non-empty expression followed by a ':' and a TypeExpression.
+// judgement
+// Initiates Recursive Descent Parsing of a line, see ParseWith.
+// Expects an optional context followed by '⊢', then a non-empty expression followed by a ':' and a TypeExpression.
+// The context, expression and type are stored as proper trees in context.Tree.Context,
+// context.Tree.Expression and context.Tree.Type. Returns a SyntaxError if the line does not follow the grammar.
+// A line that starts with "def" is a definition, which has no context and of which the type is optional.
 // context: contains the expression.
-func Judgement(context *Globals.Vars) {
-	Expr(context)
-	JudgementFunction(context)
-	TypeExpr(context)
-} // Judgement
+// typeRequired: Whether the line has to contain a ':' and a TypeExpression.
+func judgement(context *Globals.Vars, typeRequired bool) error {
+	var name *parsetree.Term
+	if context.Token == Tokens.TokenDefinition {
+		var err error
+		if name, err = DefinitionName(context); err != nil {
+			return err
+		}
+		// The type of a definition is synthesized if it is omitted.
+		typeRequired = false
+		// The name is not part of the parse-tree of the expression.
+		context.Tree.ClearTree()
+	}
+	var assumptions *parsetree.Term
+	if HasContext(context) {
+		if name != nil {
+			return syntaxError(context, Errors.InvalidDefinition, "A definition cannot have a context.")
+		}
+		var err error
+		if assumptions, err = ContextExpr(context); err != nil {
+			return err
+		}
+		// The assumptions are not part of the parse-tree of the judgement.
+		context.Tree.ClearTree()
+	}
+	var expression, err = Expr(context)
+	if err != nil {
+		return err
+	}
+	var typeTerm *parsetree.Term
+	if typeRequired || context.Token != Tokens.LexicalEndOfLine {
+		if err = JudgementFunction(context); err != nil {
+			return err
+		}
+		if typeTerm, err = TypeExpr(context); err != nil {
+			return err
+		}
+		if context.Token != Tokens.LexicalEndOfLine {
+			return syntaxError(context, Errors.InvalidTypeExpression, "Expected end of line after Type Expression.")
+		}
+	}
+	context.Tree.Name = name
+	context.Tree.Context = assumptions
+	context.Tree.Expression = expression
+	context.Tree.Type = typeTerm
+	context.Tree.UpdateSpans()
+	return nil
+}
+
+// DefinitionName
+// Parses the start of a definition: the keyword "def", followed by the name of the definition and '='.
+// Returns the name as variable term.
+// context: contains the expression.
+func DefinitionName(context *Globals.Vars) (*parsetree.Term, error) {
+	if context.DebugMode {
+		fmt.Println("   DefinitionName called")
+	}
+	if err := NextToken(context); err != nil {
+		return nil, err
+	}
+	if context.Token != Tokens.TokenVariable {
+		return nil, syntaxError(context, Errors.InvalidDefinition, "Expected the name of the definition after def.")
+	}
+	var name, err = VarExpr(context)
+	if err != nil {
+		return nil, err
+	}
+	if context.Token != Tokens.TokenEquals {
+		return nil, syntaxError(context, Errors.InvalidDefinition, "Expected = after the name of the definition.")
+	}
+	return name, NextToken(context)
+}
+
+// HasContext
+// Peeks at the tokens after the current token and checks whether one of them is a turnstile,
+// in that case the judgement starts with a context. No token is consumed.
+// context: contains the expression.
+func HasContext(context *Globals.Vars) bool {
+	if context.Token == Tokens.TokenTurnstile {
+		return true
+	}
+	for n := 0; ; n++ {
+		var token, err = context.Lexer.Peek(n)
+		if err != nil || token.Kind == Tokens.LexicalEndOfLine {
+			return false
+		}
+		if token.Kind == Tokens.TokenTurnstile {
+			return true
+		}
+	}
+}
+
+// ContextExpr
+// Parses the context of a judgement: a possibly empty, comma separated list of <lvar> ':' <type>
+// terminated by a '⊢'. Returns a Context term with a variable term for every assumption,
+// the type of an assumption is the child of its variable.
+// context: contains the expression.
+func ContextExpr(context *Globals.Vars) (*parsetree.Term, error) {
+	if context.DebugMode {
+		fmt.Println("   ContextExpr called")
+	}
+	var assumptions = parsetree.NewTerm(parsetree.Node{Token: Tokens.Context, Lexeme: "Context", Span: context.TokenSpan})
+	for context.Token != Tokens.TokenTurnstile {
+		if context.Token != Tokens.TokenVariable {
+			return nil, syntaxError(context, Errors.ExpectedVariable, "Expected variable in context.")
+		}
+		var variable, err = VarExpr(context)
+		if err != nil {
+			return nil, err
+		}
+		if context.Token != Tokens.TokenDoubleDot {
+			return nil, syntaxError(context, Errors.ExpectedJudgement, "Expected : after variable in context.")
+		}
+		if err = NextToken(context); err != nil {
+			return nil, err
+		}
+		typeTerm, err := TypeExpr(context)
+		if err != nil {
+			return nil, err
+		}
+		variable.Children = []*parsetree.Term{typeTerm}
+		assumptions.Children = append(assumptions.Children, variable)
+		assumptions.Span = assumptions.Span.Join(variable.Span.Join(typeTerm.Span))
+		if context.Token == Tokens.TokenComma {
+			if err = NextToken(context); err != nil {
+				return nil, err
+			}
+			// Another assumption is required after a comma.
+			if context.Token != Tokens.TokenVariable {
+				return nil, syntaxError(context, Errors.ExpectedVariable, "Expected variable in context.")
+			}
+		} else if context.Token != Tokens.TokenTurnstile {
+			return nil, syntaxError(context, Errors.InvalidExpression, "Expected , or ⊢ after assumption in context.")
+		}
+	}
+	return assumptions, NextToken(context)
+}
+
+// NextToken
+// Obtains the next token from the token stream of the line and stores it in the context.
+// The token stream is created on the first call.
+// context: contains the expression.
+func NextToken(context *Globals.Vars) error {
+	if context.DebugMode {
+		fmt.Println("   Lexical called")
+	}
+	if context.Lexer == nil {
+		context.Lexer = LexicalAnalyser.NewLexer(context.CurrentLine, context.LineNumber)
+	}
+	var token, err = context.Lexer.Next()
+	context.PrevToken = context.Token
+	context.Token = token.Kind
+	context.Lexeme = []rune(token.Lexeme)
+	context.TokenSpan = token.Span
+	return err
+}
+
+// syntaxError
+// Creates a SyntaxError of the given kind at the token that is currently analysed.
+// context: contains the expression.
+// kind: The kind of syntax error.
+// message: Description of the error.
+func syntaxError(context *Globals.Vars, kind int, message string) error {
+	return Errors.NewSyntaxError(kind, message, context.CurrentLine, context.TokenSpan)
+}
 
 // Expr Part of parsing <expr> in Recursive Descent Parsing.
 // Expects a non-empty <expr> using the LExpr function.
-// after that allows for an end-of-line or ':' using MsExpr.
-//  context: contains the expression.
-func Expr(context *Globals.Vars) {
+// after that allows for an end-of-line, ':' or ')' using MsExpr.
+// Returns the parsed expression.
+// context: contains the expression.
+func Expr(context *Globals.Vars) (*parsetree.Term, error) {
 	if context.DebugMode {
 		fmt.Println("   Expr called")
 	}
 	// We expect a <lvar>, left-bracket or lambda-expression.
-	LExpr(context)
+	var term, err = LExpr(context)
+	if err != nil {
+		return nil, err
+	}
 	// Allows for end-of-line or ':'.
-	MsExpr(context)
+	return MsExpr(context, term)
 }
 
 // JudgementFunction
-// "Peeks" in the string to be lexically analysed and checks whether the next token
-// is a JudgementFunction. If so, the token is parsed and Recursive Descent continues accordingly.
+// Checks whether the current token is a JudgementFunction.
+// If so, the token is parsed and Recursive Descent continues accordingly.
 // Else, there is a syntax error.
 // context: contains the expression.
-func JudgementFunction(context *Globals.Vars) bool {
-    if context.DebugMode {
+func JudgementFunction(context *Globals.Vars) error {
+	if context.DebugMode {
 		fmt.Println(" JudgementFunction called")
 	}
 	if context.Tree.IndexDoubleDot != -1 {
-		fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: double Judgement Function (:).")
-		os.Exit(1)
+		return syntaxError(context, Errors.ExpectedJudgement, "double Judgement Function (:).")
 	} else if context.Token == Tokens.TokenDoubleDot {
-		context.Tree.AddToken(Tokens.TokenDoubleDot, "Judge", 0)
-            
-        context.Tree.IndexDoubleDot = len(context.Tree.Nodes)
+		context.Tree.AddToken(Tokens.TokenDoubleDot, "Judge", 0, context.TokenSpan)
 
-		context.Token = LexicalAnalyser.LexicalAnalyser(context)
-		return true
-	} else {
-		fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: Expected Judgement Function (:).")
-		os.Exit(1)
+		context.Tree.IndexDoubleDot = len(context.Tree.Nodes)
+
+		return NextToken(context)
 	}
-	return false
+	return syntaxError(context, Errors.ExpectedJudgement, "Expected Judgement Function (:).")
 }
 
 // MsExpr
-// Expr-prime, is allowed to be empty; meaning an end-of-line or ':' token is allowed
-// If end-of-line or ':' is found, the parsing will stop or continue with <type> respectively.
-// Else, the Recursive Descent is continued with an <expr>.
+// Expr-prime, is allowed to be empty; meaning an end-of-line, ':' or ')' token is allowed
+// If end-of-line, ':' or ')' is found, the parsing of this <expr> stops.
+// Else, the Recursive Descent is continued with an <expr> that is applied to the expression parsed so far,
+// hence application is left associative.
+// Returns the parsed expression.
 // context: Contains the whole expression.
-func MsExpr(context *Globals.Vars) {
+// applied: The expression parsed so far.
+func MsExpr(context *Globals.Vars, applied *parsetree.Term) (*parsetree.Term, error) {
 	if context.DebugMode {
 		fmt.Println("   MsExpr called")
 	}
 	if context.Token == Tokens.LexicalEndOfLine || context.Token == Tokens.TokenDoubleDot {
-		return
+		return applied, nil
+	} else if context.Token == Tokens.TokenRightBracket {
+		if context.CountBrackets <= 0 {
+			return nil, syntaxError(context, Errors.UnbalancedBrackets, "No brackets to close.")
+		}
+		return applied, nil
 	} else {
 		if context.DebugMode {
-			fmt.Println("Line not finished, found: " + string(context.ReadChar))
+			fmt.Println("Line not finished, found: " + string(context.Lexeme))
 		}
 	}
 	// No end of line; expected non-empty expression!
-	LExpr(context)
+	var argument, err = LExpr(context)
+	if err != nil {
+		return nil, err
+	}
 	// Possible empty expression allowed.
-	MsExpr(context)
+	return MsExpr(context, parsetree.NewApplication(applied, argument))
 }
 
 // LExpr
 // Determines the next step in recursive descent.
-// Contains all possible continuations for <expr> namely, a <lvar>, ( <expr>) or lambda <lvar>'^'<type> <expr>.
+// Contains all possible continuations for <expr> namely, a <lvar>, ( <expr>) or lambda <lvar>['^'<type>] <expr>.
 // The <expr> <expr> continuations is handled using MsExpr
+// Returns the parsed expression, the body of a lambda extends as far to the right as possible.
 // context: Contains the whole expression.
-func LExpr(context *Globals.Vars) {
+func LExpr(context *Globals.Vars) (*parsetree.Term, error) {
 	if context.DebugMode {
 		fmt.Println("   LExpr called")
 	}
 	if context.ExpectVariable && context.Token != Tokens.TokenVariable {
-		fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: Expected variable")
-		os.Exit(1)
-	}
-	if context.ExpectUp && context.Token != Tokens.TypeSymbol {
-		fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: Expected ^ but got: "+string(context.CurrentLine[context.Index]))
-		os.Exit(1)
+		return nil, syntaxError(context, Errors.ExpectedVariable, "Expected variable")
 	}
 	switch context.Token {
 	case Tokens.TokenLambda:
-		context.Tree.AddToken(Tokens.TokenLambda, string('λ'), 0)
-		context.Token = LexicalAnalyser.LexicalAnalyser(context)
+		var lambdaSpan = context.TokenSpan
+		context.Tree.AddToken(Tokens.TokenLambda, string('λ'), 0, context.TokenSpan)
+		if err := NextToken(context); err != nil {
+			return nil, err
+		}
 		// Force the next LExpr to contain a variable otherwise we have a syntax error.
 		context.ExpectVariable = true
-		LExpr(context)
-		// Force the next LExpr to contain a '^' otherwise we have a syntax error.
-		context.ExpectUp = true
-		LExpr(context)
-		// TypeExpression required.
-		TypeExpr(context)
+		var variable, err = LExpr(context)
+		if err != nil {
+			return nil, err
+		}
+		// The type of the variable is optional, it is inferred if there is no '^'.
+		if context.Token == Tokens.TypeSymbol {
+			if err = NextToken(context); err != nil {
+				return nil, err
+			}
+			// TypeExpression required.
+			typeTerm, err := TypeExpr(context)
+			if err != nil {
+				return nil, err
+			}
+			variable.Children = []*parsetree.Term{typeTerm}
+		}
 		// Non-empty expression required.
-		LExpr(context)
-		break
+		body, err := Expr(context)
+		if err != nil {
+			return nil, err
+		}
+		return parsetree.NewLambda(lambdaSpan, variable, body), nil
 	case Tokens.TokenVariable:
-		VarExpr(context)
-		break
+		return VarExpr(context)
 	case Tokens.TokenLeftBracket:
 		// Increment shows that there is an extra open bracket.
 		context.CountBrackets++
-		context.Token = LexicalAnalyser.LexicalAnalyser(context)
+		if err := NextToken(context); err != nil {
+			return nil, err
+		}
 		// Non-empty expression required.
-		LExpr(context)
-		// Expected non-empty expression.
-		LExpr(context)
-		break
-	case Tokens.TokenRightBracket:
-		// Decrement shows that there is an extra closed bracket.
+		var term, err = Expr(context)
+		if err != nil {
+			return nil, err
+		}
+		if context.Token != Tokens.TokenRightBracket {
+			return nil, syntaxError(context, Errors.UnbalancedBrackets, "Expected Closing Bracket.")
+		}
+		// Decrement shows that the bracket is closed.
 		context.CountBrackets--
-		if context.CountBrackets < 0 {
-			fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: No brackets to close.")
-			os.Exit(1)
+		return term, NextToken(context)
+	case Tokens.TokenRightBracket:
+		if context.CountBrackets <= 0 {
+			return nil, syntaxError(context, Errors.UnbalancedBrackets, "No brackets to close.")
 		}
-		context.Token = LexicalAnalyser.LexicalAnalyser(context)
-		break
+		return nil, syntaxError(context, Errors.EmptyExpression, "Expected Non-empty Expression.")
 	case Tokens.TypeSymbol:
-		context.ExpectUp = false
-		context.Token = LexicalAnalyser.LexicalAnalyser(context)
-		break
+		return nil, syntaxError(context, Errors.InvalidExpression, "Expected an expression but got '^', it can only follow the variable of a lambda.")
 	case Tokens.TokenUVar:
 		if context.PrevToken == Tokens.TokenUVar {
-			fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: Expected TermFunction (->).")
-			os.Exit(1)
-		} else {
-			fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: Cannot Parse Uvar in Expression.")
-			os.Exit(1)
+			return nil, syntaxError(context, Errors.ExpectedFunction, "Expected TermFunction (->).")
 		}
+		return nil, syntaxError(context, Errors.InvalidExpression, "Cannot Parse Uvar in Expression.")
 	case Tokens.LexicalEndOfLine:
 		if context.CountBrackets > 0 {
-			fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: Expected Closing Bracket.")
-			os.Exit(1)
+			return nil, syntaxError(context, Errors.UnbalancedBrackets, "Expected Closing Bracket.")
 		}
-		fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: Expected Non-empty Expression.")
-		os.Exit(1)
+		return nil, syntaxError(context, Errors.EmptyExpression, "Expected Non-empty Expression.")
+	case Tokens.TokenDefinition:
+		return nil, syntaxError(context, Errors.InvalidDefinition, "def can only start a line, it cannot be used as variable.")
+	case Tokens.TokenImport:
+		return nil, syntaxError(context, Errors.InvalidImport, "import can only start a line, it cannot be used as variable.")
+	case Tokens.TokenEquals:
+		return nil, syntaxError(context, Errors.InvalidDefinition, "= can only follow the name of a definition.")
 	case Tokens.SyntaxError:
-		fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: unknown character.")
-		os.Exit(1)
+		return nil, syntaxError(context, Errors.UnknownCharacter, "unknown character.")
 	default:
-		fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: unknown token.")
-		os.Exit(1)
+		return nil, syntaxError(context, Errors.InvalidExpression, "Expected an expression but got '"+string(context.Lexeme)+"'.")
 	} // switch --- Token
 } // LExpr
 
 // VarExpr
 // Prints the read in variable and resets the lexeme and ExpectedVariable members.
 // Since a <lvar> is a terminal, the next token is obtained at the end of this function.
+// Returns the variable as term.
 // context: Contains the whole expression.
-func VarExpr(context *Globals.Vars) {
+func VarExpr(context *Globals.Vars) (*parsetree.Term, error) {
 	if context.DebugMode {
 		fmt.Println("   VarExpr called")
 	}
-	context.Tree.AddToken(Tokens.TokenVariable, string(context.Lexeme), CalcBrack(context))
+	var variable = parsetree.NewVariable(Tokens.TokenVariable, string(context.Lexeme), context.TokenSpan)
+	context.Tree.AddToken(Tokens.TokenVariable, string(context.Lexeme), CalcBrack(context), context.TokenSpan)
 	context.Lexeme = nil
 	context.ExpectVariable = false
-	context.Token = LexicalAnalyser.LexicalAnalyser(context)
+	return variable, NextToken(context)
 } // VarExpr
 
 // TypeExpr
 // Obtains the next token from LexicalAnalyser and determines the next step in recursive descent accordingly.
 // Contains all possible continuations for <type> namely <uvar>, ( <type> ) and <type> "->" <type>.
-// The <type> "->" <type> continuation is handled using TypeFunction which "Peeks" to see if the next token
+// The <type> "->" <type> continuation is handled using TypeFunction which checks whether the current token
 // corresponds with a "->", if so an extra call to TypeExpr is made after parsing the "->".
+// Returns the parsed type, the function type is right associative.
 // context: Contains the whole expression.
-func TypeExpr(variables *Globals.Vars) {
+func TypeExpr(variables *Globals.Vars) (*parsetree.Term, error) {
 	if variables.DebugMode {
 		fmt.Println("   TypeExpr called")
 	}
 	switch variables.Token {
 	case Tokens.TokenUVar:
-		variables.Tree.AddToken(Tokens.TokenUVar, string(variables.Lexeme), CalcBrack(variables))
+		var uvar = parsetree.NewVariable(Tokens.TokenUVar, string(variables.Lexeme), variables.TokenSpan)
+		variables.Tree.AddToken(Tokens.TokenUVar, string(variables.Lexeme), CalcBrack(variables), variables.TokenSpan)
 		// Reset.
 		variables.Lexeme = nil
-		variables.Token = LexicalAnalyser.LexicalAnalyser(variables)
+		if err := NextToken(variables); err != nil {
+			return nil, err
+		}
 
 		// Check for possible <type> "->" <type> continuation.
-		if TypeFunction(variables) {
-			TypeExpr(variables)
-		}
-		break
+		return typeContinuation(variables, uvar)
 	case Tokens.TokenLeftBracket:
 		// Increment shows that there is an extra open bracket.
 		variables.CountBrackets++
 
-		variables.Token = LexicalAnalyser.LexicalAnalyser(variables)
+		if err := NextToken(variables); err != nil {
+			return nil, err
+		}
 
 		// Expected nested TypeExpr.
-		TypeExpr(variables)
-		if variables.Token == Tokens.TokenRightBracket {
-			variables.CountBrackets--
-			variables.Token = LexicalAnalyser.LexicalAnalyser(variables)
-			if TypeFunction(variables) {
-				TypeExpr(variables)
-			}
+		var nested, err = TypeExpr(variables)
+		if err != nil {
+			return nil, err
+		}
+		if variables.Token != Tokens.TokenRightBracket {
+			return nil, syntaxError(variables, Errors.UnbalancedBrackets, "Expected Closing Bracket.")
 		}
-		break
+		variables.CountBrackets--
+		if err = NextToken(variables); err != nil {
+			return nil, err
+		}
+		return typeContinuation(variables, nested)
 	case Tokens.TokenRightBracket:
-		fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: Expected UVar.")
-		os.Exit(1)
+		return nil, syntaxError(variables, Errors.InvalidTypeExpression, "Expected UVar.")
 	case Tokens.TokenVariable:
-		fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: LVar cannot be parsed in Type Expression.")
-		os.Exit(1)
+		return nil, syntaxError(variables, Errors.InvalidTypeExpression, "LVar cannot be parsed in Type Expression.")
 	case Tokens.TokenLambda:
-		fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: Lambda cannot be parsed in Type Expression.")
-		os.Exit(1)
+		return nil, syntaxError(variables, Errors.InvalidTypeExpression, "Lambda cannot be parsed in Type Expression.")
 	case Tokens.LexicalEndOfLine:
-		fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: Type Expression cannot be empty.")
-		os.Exit(1)
+		return nil, syntaxError(variables, Errors.EmptyExpression, "Type Expression cannot be empty.")
 	default:
-		fmt.Fprintf(os.Stderr, "%s\n", "Syntax error: Expected Type Expression")
-		os.Exit(1)
+		return nil, syntaxError(variables, Errors.InvalidTypeExpression, "Expected Type Expression")
+	}
+}
+
+// typeContinuation
+// Parses the right-hand side of a <type> "->" <type> if TypeFunction found a "->".
+// Returns the domain if there is no "->", else the function type.
+// context: Contains the whole expression.
+// domain: The type parsed so far.
+func typeContinuation(context *Globals.Vars, domain *parsetree.Term) (*parsetree.Term, error) {
+	var isFunction, err = TypeFunction(context)
+	if err != nil || !isFunction {
+		return domain, err
 	}
+	codomain, err := TypeExpr(context)
+	if err != nil {
+		return nil, err
+	}
+	return parsetree.NewFunction(domain, codomain), nil
 }
 
 // TypeFunction
-// "Peeks" in the string to be lexically analysed and checks whether the next token
-// is a TypeFunction. If so, the token is parsed and Recursive Descent continues accordingly.
-// Else, the "Peek" is reverted since a token other than a TypeFunction was found,
-// which allows the LexicalAnalyser to read this token again on the next call.
+// Checks whether the current token is a TypeFunction.
+// If so, the token is parsed and Recursive Descent continues accordingly.
+// Else, the current token is left as is for the caller.
 // context: Contains the whole expression.
-func TypeFunction(context *Globals.Vars) bool {
+func TypeFunction(context *Globals.Vars) (bool, error) {
 	if context.Token == Tokens.TokenFunction {
-		context.Tree.AddToken(Tokens.TokenFunction, "->", 0)
+		context.Tree.AddToken(Tokens.TokenFunction, "->", 0, context.TokenSpan)
 		//get the next token
-		context.Token = LexicalAnalyser.LexicalAnalyser(context)
-		return true
+		return true, NextToken(context)
 	}
-	return false
+	return false, nil
 }
 
 // CalcBrack
-// Given the current index in the global variables we determine the amount of brackets among this token
+// Given the span of the current token we determine the amount of brackets among this token
 // an opening bracket on the left increments the counter
 // a closing bracket on the right decrements the counter
 // returns an integer, that counts the difference between opening and closing brackets around the token
 // context: Contains the whole expression.
 func CalcBrack(context *Globals.Vars) int {
 	var counter = 0
-	var index = context.Index
-	for i := index - 1; i >= 0; i-- {
+	for i := context.TokenSpan.Start - 1; i >= 0; i-- {
 		if context.CurrentLine[i] == '(' {
 			counter++
 			// we ignore the first lambda to the left since the counter for a lambda token is stored in the lambda variable
@@ -270,7 +474,7 @@ func CalcBrack(context *Globals.Vars) int {
 			break
 		}
 	} // for --- walk back
-	for i := index + len(context.Lexeme); i < len(context.CurrentLine); i++ {
+	for i := context.TokenSpan.End; i < len(context.CurrentLine); i++ {
 		if context.CurrentLine[i] == ')' {
 			counter--
 		} else if context.CurrentLine[i] == ' ' {
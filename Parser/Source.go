@@ -0,0 +1,134 @@
+/*
+ * Parser and Lexical Analyser Source.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package parser
+
+import (
+	"Parser-TypeChecking/Errors"
+	"Parser-TypeChecking/LexicalAnalyser"
+	"Parser-TypeChecking/Tokens"
+	"strings"
+)
+
+// Source
+// A judgement of an input file. A line that ends with a '\' is continued on the next line,
+// the lines of such a judgement are joined by '\n'.
+type Source struct {
+	// Line number of the first line of the judgement, starting at 1.
+	Line int
+	// The lines of the judgement including comments and continuations, can be parsed by ParseWith.
+	Text string
+}
+
+// Sources
+// Groups the lines of an input file into judgements, blank lines and lines with only a comment are skipped.
+// lines: All lines of the input file.
+func Sources(lines []string) []Source {
+	var sources []Source
+	var current []string
+	for i, line := range lines {
+		if len(current) == 0 && IsBlank(line) {
+			continue
+		}
+		current = append(current, line)
+		if Continued(line) && i+1 < len(lines) {
+			continue
+		}
+		sources = append(sources, Source{Line: i + 2 - len(current), Text: strings.Join(current, "\n")})
+		current = nil
+	}
+	return sources
+} // Sources
+
+// commentStart
+// Returns the index at which the comment on the line starts, "--" or '#', or -1 if the line has no comment.
+// line: A single line of the input.
+func commentStart(line string) int {
+	var index = strings.Index(line, "--")
+	var hash = strings.IndexRune(line, '#')
+	if index < 0 || (hash >= 0 && hash < index) {
+		return hash
+	}
+	return index
+}
+
+// withoutComment
+// Returns the line without its comment and without spaces around the rest.
+// line: A single line of the input.
+func withoutComment(line string) string {
+	if index := commentStart(line); index >= 0 {
+		line = line[:index]
+	}
+	return strings.TrimSpace(line)
+}
+
+// IsBlank
+// Returns whether the line contains nothing but spaces and a comment.
+// line: A single line of the input.
+func IsBlank(line string) bool {
+	return withoutComment(line) == ""
+}
+
+// Continued
+// Returns whether the judgement on the line is continued on the next line, i.e. the line ends with a '\'.
+// line: A single line of the input.
+func Continued(line string) bool {
+	return strings.HasSuffix(withoutComment(line), "\\")
+}
+
+// IsDefinition
+// Returns whether the judgement is a definition, i.e. it starts with the keyword "def".
+// text: The judgement, possibly spanning several lines.
+func IsDefinition(text string) bool {
+	var token, err = LexicalAnalyser.NewLexer([]rune(text), 0).Peek(0)
+	return err == nil && token.Kind == Tokens.TokenDefinition
+}
+
+// IsImport
+// Returns whether the judgement is an import of another file, i.e. it starts with the keyword "import".
+// text: The judgement, possibly spanning several lines.
+func IsImport(text string) bool {
+	var token, err = LexicalAnalyser.NewLexer([]rune(text), 0).Peek(0)
+	return err == nil && token.Kind == Tokens.TokenImport
+}
+
+// ImportPath
+// Returns the path of an import such as import "prelude.lam" and the span of the quoted path,
+// or a SyntaxError if the path is not in double quotes or the line continues after it.
+// source: The import, see IsImport.
+func ImportPath(source Source) (string, Tokens.Span, error) {
+	var line = []rune(source.Text)
+	// The path follows the keyword, which is the first token.
+	var keyword, _ = LexicalAnalyser.NewLexer(line, source.Line).Peek(0)
+	var index = keyword.Span.End
+	for index < len(line) && (line[index] == ' ' || line[index] == '\t') {
+		index++
+	}
+	var span = Tokens.Span{Line: source.Line, Start: index, End: index + 1}
+	if index >= len(line) || line[index] != '"' {
+		return "", span, Errors.NewSyntaxError(Errors.InvalidImport,
+			"Expected a path in double quotes after import.", line, span)
+	}
+	var end = index + 1
+	for end < len(line) && line[end] != '"' && line[end] != '\n' {
+		end++
+	}
+	span.End = end
+	if end >= len(line) || line[end] != '"' {
+		return "", span, Errors.NewSyntaxError(Errors.InvalidImport, "Missing '\"' at the end of the path.", line, span)
+	}
+	span.End = end + 1
+	if end == index+1 {
+		return "", span, Errors.NewSyntaxError(Errors.InvalidImport, "The path of an import cannot be empty.", line, span)
+	}
+	if !IsBlank(string(line[end+1:])) {
+		var rest = Tokens.Span{Line: source.Line, Start: end + 1, End: len(line)}
+		for line[rest.Start] == ' ' || line[rest.Start] == '\t' {
+			rest.Start++
+		}
+		return "", rest, Errors.NewSyntaxError(Errors.InvalidImport, "Expected nothing but a comment after the path.", line, rest)
+	}
+	return string(line[index+1 : end]), span, nil
+}
@@ -0,0 +1,102 @@
+/*
+ * Parser and Lexical Analyser Source_test.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSources
+// Groups lines into judgements, lines that end with a '\' are joined with the next line.
+func TestSources(t *testing.T) {
+	var tests = []struct {
+		name  string
+		lines []string
+		want  []Source
+	}{
+		{"one judgement per line", []string{`x : A`, `y : B`}, []Source{{1, `x : A`}, {2, `y : B`}}},
+		{"blank lines and comments", []string{``, `# comment`, `x : A`, `  -- comment`, ``, `y : B`},
+			[]Source{{3, `x : A`}, {6, `y : B`}}},
+		{"continued", []string{`\x \`, `  x \`, `  : A -> A`, `y : B`},
+			[]Source{{1, "\\x \\\n  x \\\n  : A -> A"}, {4, `y : B`}}},
+		{"continued before a comment", []string{`\x \ # body below`, `  x : A -> A`},
+			[]Source{{1, "\\x \\ # body below\n  x : A -> A"}}},
+		{"continued onto a blank line", []string{`\x \`, ``, `  x : A -> A`},
+			[]Source{{1, "\\x \\\n"}, {3, `  x : A -> A`}}},
+		{"continued on the last line", []string{`x : A`, `\x \`}, []Source{{1, `x : A`}, {2, `\x \`}}},
+		{"lambda at the end is not a continuation", []string{`x : A \x`, `y : B`},
+			[]Source{{1, `x : A \x`}, {2, `y : B`}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Sources(test.lines); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Sources(%q) = %q, want %q", test.lines, got, test.want)
+			}
+		})
+	}
+}
+
+// TestKeywords
+// Recognises definitions and imports by their first token.
+func TestKeywords(t *testing.T) {
+	var tests = []struct {
+		text       string
+		definition bool
+		imports    bool
+	}{
+		{`def id = \x x`, true, false},
+		{`  def id = \x x`, true, false},
+		{`import "prelude.lam"`, false, true},
+		{`import"prelude.lam"`, false, true},
+		{`import x : A`, false, true},
+		{`imports x : A`, false, false},
+		{`define : A`, false, false},
+		{`# import "prelude.lam"`, false, false},
+		{`\x import`, false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			if got := IsDefinition(test.text); got != test.definition {
+				t.Errorf("IsDefinition(%q) = %v, want %v", test.text, got, test.definition)
+			}
+			if got := IsImport(test.text); got != test.imports {
+				t.Errorf("IsImport(%q) = %v, want %v", test.text, got, test.imports)
+			}
+		})
+	}
+}
+
+// TestImportPath
+// Extracts the path of an import, or reports where the import is malformed.
+func TestImportPath(t *testing.T) {
+	var tests = []struct {
+		text string
+		// The path, empty if an error is expected.
+		want  string
+		start int
+		end   int
+	}{
+		{`import "prelude.lam"`, "prelude.lam", 7, 20},
+		{`  import   "lib/a b.lam" # comment`, "lib/a b.lam", 11, 24},
+		{`import prelude.lam`, "", 7, 8},
+		{`import "prelude.lam`, "", 7, 19},
+		{`import ""`, "", 7, 9},
+		{`import "a.lam" x`, "", 15, 16},
+		{`import`, "", 6, 7},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			var path, span, err = ImportPath(Source{Line: 1, Text: test.text})
+			if (err == nil) != (test.want != "") || path != test.want {
+				t.Fatalf("ImportPath(%q) = %q, %v, want %q", test.text, path, err, test.want)
+			}
+			if span.Start != test.start || span.End != test.end {
+				t.Errorf("ImportPath(%q) spans %d-%d, want %d-%d", test.text, span.Start, span.End, test.start, test.end)
+			}
+		})
+	}
+}
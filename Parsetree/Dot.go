@@ -0,0 +1,94 @@
+/*
+ * Parser and Lexical Analyser Dot.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package parsetree
+
+import (
+	"Parser-TypeChecking/Tokens"
+	"fmt"
+	"strings"
+)
+
+// DotLabel
+// Returns the label of the node in a Graphviz graph.
+func (node Node) DotLabel() string {
+	switch node.Token {
+	case Tokens.TokenDoubleDot:
+		return "Judge"
+	case Tokens.Application:
+		return "Application"
+	case Tokens.TokenLambda:
+		return "Lambda"
+	case Tokens.TokenVariable:
+		return "Var " + node.Lexeme
+	case Tokens.TokenUVar:
+		return "Type " + node.Lexeme
+	case Tokens.TokenFunction:
+		return "->"
+	case Tokens.Context:
+		return "Context"
+	}
+	return node.Lexeme
+}
+
+// dotShape
+// Returns the Graphviz shape of the node, the judgement and the type nodes stand out.
+func (node Node) dotShape() string {
+	switch node.Token {
+	case Tokens.TokenDoubleDot, Tokens.Context:
+		return "box"
+	case Tokens.TokenLambda:
+		return "diamond"
+	case Tokens.TokenUVar, Tokens.TokenFunction:
+		return "box, style=rounded"
+	}
+	return "ellipse"
+}
+
+// ToDot
+// Returns the parse tree of the judgement as a Graphviz graph in the DOT language. The root has the name of a
+// definition, the context, the expression and the type as children. A lambda has its variable, with the annotated
+// type as child, and its body as children, an application its function and its argument.
+// name: Name of the graph.
+func (judgement *Judgement) ToDot(name string) string {
+	var output strings.Builder
+	fmt.Fprintf(&output, "digraph %s {\n", dotQuote(name))
+	output.WriteString("    node [fontname=\"Helvetica\"];\n")
+	output.WriteString("    n0 [label=\"Judge\", shape=box];\n")
+	var count = 1
+	if judgement.Name != nil {
+		fmt.Fprintf(&output, "    n%d [label=%s, shape=ellipse];\n", count, dotQuote("Def "+judgement.Name.Lexeme))
+		fmt.Fprintf(&output, "    n0 -> n%d;\n", count)
+		count++
+	}
+	for _, part := range []*Term{judgement.Context, judgement.Expression, judgement.Type} {
+		if part != nil {
+			fmt.Fprintf(&output, "    n0 -> n%d;\n", count)
+			part.writeDot(&output, &count)
+		}
+	}
+	output.WriteString("}\n")
+	return output.String()
+} // ToDot
+
+// writeDot
+// Writes the node of the term and the nodes of its children with the edges to them, used by ToDot.
+// output: The graph.
+// count: Amount of nodes written so far, the node of the term gets this number.
+func (term *Term) writeDot(output *strings.Builder, count *int) {
+	var index = *count
+	*count++
+	fmt.Fprintf(output, "    n%d [label=%s, shape=%s];\n", index, dotQuote(term.DotLabel()), term.dotShape())
+	for _, child := range term.Children {
+		fmt.Fprintf(output, "    n%d -> n%d;\n", index, *count)
+		child.writeDot(output, count)
+	}
+}
+
+// dotQuote
+// Returns text as a quoted DOT identifier.
+func dotQuote(text string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(text) + "\""
+}
@@ -0,0 +1,26 @@
+/*
+ * Parser and Lexical Analyser Judgement.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package parsetree
+
+// Judgement
+// Result of parsing a single line: an optional context, the expression and an optional type.
+// A definition "def {lvar} = {expr}" is a judgement with a name, but without context.
+type Judgement struct {
+	// Line number of the parsed line in the input file, 0 if the line was not read from a file.
+	Line int
+	// The parsed line.
+	Source []rune
+	// Name of the definition, nil if the line is not a definition.
+	Name *Term
+	// Context of the judgement, nil if the line has no context.
+	Context *Term
+	// Expression part of the judgement.
+	Expression *Term
+	// Type part of the judgement, nil if the line has no type.
+	Type *Term
+	// Flat depth encoded parse tree of the expression and type.
+	Tree ParseTree
+}
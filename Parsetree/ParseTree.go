@@ -24,6 +24,8 @@ type Node struct {
 	IsSecondInApplication bool
 	// BracketCounter denotes the amount of brackets around this node, >0 if there are more opening than closing brackets.
 	BracketCounter int
+	// Span denotes where in the analysed line this node came from, for applications the span covers all children.
+	Span Tokens.Span
 }
 
 // TokenToString
@@ -49,8 +51,18 @@ type ParseTree struct {
 	IndexDoubleDot int
 	// All Nodes in the tree.
 	Nodes []Node
+	// Name of the definition, nil if the line is not a definition.
+	Name *Term
+	// Context of the judgement as a proper tree, nil if the judgement has no context.
+	Context *Term
+	// Expression part of the judgement as a proper tree.
+	Expression *Term
+	// Type part of the judgement as a proper tree.
+	Type *Term
 	// Depth at which the last node was added.
 	currentDepth int
+	// Span of the token that is currently added.
+	span Tokens.Span
 }
 
 // AddToken
@@ -58,7 +70,9 @@ type ParseTree struct {
 // token: Type of the token.
 // lexeme: the provided lexeme as string.
 // bracketsCounter: How many brackets are currently opened.
-func (tree *ParseTree) AddToken(token int, lexeme string, bracketsCounter int) {
+// span: Position of the token in the analysed line.
+func (tree *ParseTree) AddToken(token int, lexeme string, bracketsCounter int, span Tokens.Span) {
+	tree.span = span
 	if len(tree.Nodes) > 0 {
 		// Get the previous node.
 		var prevNode = tree.Nodes[len(tree.Nodes)-1]
@@ -71,13 +85,13 @@ func (tree *ParseTree) AddToken(token int, lexeme string, bracketsCounter int) {
 
 		if token == Tokens.TokenFunction {
 			// Functions are added immediately.
-			newNode = Node{token, lexeme, tree.currentDepth, false, false, bracketsCounter}
+			newNode = Node{token, lexeme, tree.currentDepth, false, false, bracketsCounter, tree.span}
 			tree.Nodes = append(tree.Nodes, newNode)
 			return
 		}
 		if token == Tokens.TokenDoubleDot {
 			// Added immediately, needs extra information for adding types correctly.
-			newNode = Node{token, lexeme, tree.currentDepth, false, false, bracketsCounter}
+			newNode = Node{token, lexeme, tree.currentDepth, false, false, bracketsCounter, tree.span}
 			tree.Nodes = append(tree.Nodes, newNode)
 			return
 		}
@@ -94,12 +108,12 @@ func (tree *ParseTree) AddToken(token int, lexeme string, bracketsCounter int) {
 			tree.InjectNodeAtIndex(prevNode, 0)
 			// Apply the second part of the Judgement to the Judgement Expression.
 			tree.currentDepth = 1
-			newNode = Node{token, lexeme, tree.currentDepth, false, false, bracketsCounter}
+			newNode = Node{token, lexeme, tree.currentDepth, false, false, bracketsCounter, tree.span}
 			tree.Nodes = append(tree.Nodes, newNode)
 			break
 		case Tokens.TokenLambda:
 			// The new node is a function argument and added on the same level as the lambda.
-			newNode = Node{token, lexeme, tree.currentDepth, false, false, bracketsCounter}
+			newNode = Node{token, lexeme, tree.currentDepth, false, false, bracketsCounter, tree.span}
 			tree.Nodes = append(tree.Nodes, newNode)
 			break
 		case Tokens.TokenFunction:
@@ -175,7 +189,7 @@ func (tree *ParseTree) AddToken(token int, lexeme string, bracketsCounter int) {
 		} // switch
 	} else {
 		// Add first node.
-		var newNode = Node{token, lexeme, tree.currentDepth, false, false, bracketsCounter}
+		var newNode = Node{token, lexeme, tree.currentDepth, false, false, bracketsCounter, tree.span}
 		tree.Nodes = append(tree.Nodes, newNode)
 	}
 }
@@ -202,7 +216,7 @@ func (tree *ParseTree) UVarFunctToNestedFunc(prevNode *Node, indexAppliedNode in
 	// Inject the function at the applied location.
 	tree.InjectNodeAtIndex(*(prevNode), indexAppliedNode)
 	// Add the new Node, applied in the injected function as the second child.
-	newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter}
+	newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter, tree.span}
 	tree.Nodes = append(tree.Nodes, newNode)
 }
 
@@ -223,7 +237,7 @@ func (tree *ParseTree) UVarFuncToPrevUVar(prevNode *Node, token int, lexeme stri
 	// Inject function at correct position
 	tree.InjectNodeAtIndex(*(prevNode), len(tree.Nodes)-1)
 	// Add the new Node, applied in the injected function as the second child.
-	newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter}
+	newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter, tree.span}
 	tree.Nodes = append(tree.Nodes, newNode)
 	return
 }
@@ -262,7 +276,7 @@ func (tree *ParseTree) UVarFuncToBracketNode(prevNode *Node, indexBracketNode in
 		tree.currentDepth = prevNode.Depth + 1
 	}
 
-	newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter}
+	newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter, tree.span}
 	tree.Nodes = append(tree.Nodes, newNode)
 }
 
@@ -275,7 +289,7 @@ func (tree *ParseTree) UVarFuncToBracketNode(prevNode *Node, indexBracketNode in
 // bracketsCounter: denotes the amount of opened brackets.
 func (tree *ParseTree) ApplyToClosestLambda(token int, lexeme string, bracketsCounter int) {
 	var newNode Node
-	newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter}
+	newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter, tree.span}
 	for i := len(tree.Nodes) - 1; newNode.BracketCounter < 0 && i >= 0; i-- {
 		if tree.Nodes[i].BracketCounter > 0 {
 			tree.RemoveRedundantBrackets(&tree.Nodes[i], &newNode)
@@ -286,14 +300,14 @@ func (tree *ParseTree) ApplyToClosestLambda(token int, lexeme string, bracketsCo
 			// We create a new application to nest the lambda in, this means we pass on any possible remaining
 			// brackets on the lambda.
 			newNode = Node{Tokens.Application, "Apply", tree.Nodes[i].Depth, tree.Nodes[i].IsInApplication,
-				tree.Nodes[i].IsSecondInApplication, tree.Nodes[i+1].BracketCounter}
+				tree.Nodes[i].IsSecondInApplication, tree.Nodes[i+1].BracketCounter, Tokens.Span{}}
 			tree.Nodes[i+1].BracketCounter = 0
 			tree.currentDepth = tree.Nodes[i].Depth + 1
 			tree.IncrementDepthFromIndex(i)
 			tree.Nodes[i].IsInApplication = true
 			tree.Nodes[i].IsSecondInApplication = false
 			tree.InjectNodeAtIndex(newNode, i)
-			newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter}
+			newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter, tree.span}
 			tree.Nodes = append(tree.Nodes, newNode)
 			break
 		}
@@ -303,6 +317,7 @@ func (tree *ParseTree) ApplyToClosestLambda(token int, lexeme string, bracketsCo
 // IsPrevLambda
 // Checks whether the previous two tokens have made a lambda expr, if so, then the new token will be nested
 // underneath this lambda. The new token is created with the provided token, lexeme and bracketscounter.
+// If the new token is not a type, the lambda has no annotated type and the new token is applied as its body.
 // The depth is determined using currentDepth in the tree.
 // token: of the new term.
 // lexeme: of the new term.
@@ -311,10 +326,15 @@ func (tree *ParseTree) IsPrevLambda(token int, lexeme string, bracketsCounter in
 	// Check if the previous 2 tokens have made up a lambda expr.
 	var newNode Node
 	if len(tree.Nodes) > 1 && tree.Nodes[len(tree.Nodes)-2].Token == Tokens.TokenLambda {
+		if token != Tokens.TokenUVar {
+			// The body of a lambda without type is applied in the same way as after its type.
+			tree.ApplyToClosestLambda(token, lexeme, bracketsCounter)
+			return true
+		}
 		// The new node is the first underneath the lambda expression, hence we increment the depth.
 		tree.currentDepth++
 		// Add new node to tree.
-		newNode = Node{token, lexeme, tree.currentDepth, false, false, bracketsCounter}
+		newNode = Node{token, lexeme, tree.currentDepth, false, false, bracketsCounter, tree.span}
 		tree.Nodes = append(tree.Nodes, newNode)
 		return true
 	}
@@ -336,12 +356,12 @@ func (tree *ParseTree) ApplyToBracketNode(indexBracketNode int, token int, lexem
 	tree.IncrementDepthFromIndex(indexBracketNode)
 	// Create the new node at new depth.
 	tree.currentDepth = oldDepthAtApplication + 1
-	newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter}
+	newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter, tree.span}
 	// Calculate remaining brackets, if the new node contains closing brackets it may remove brackets
 	// on the node to which we apply.
 	var remainingBrackets = tree.RemoveRedundantBrackets(&tree.Nodes[indexBracketNode], &newNode)
 	// Create application node, pass on possible remaining brackets.
-	var application = Node{Tokens.Application, "Apply", oldDepthAtApplication, false, false, remainingBrackets}
+	var application = Node{Tokens.Application, "Apply", oldDepthAtApplication, false, false, remainingBrackets, Tokens.Span{}}
 	// remove the brackets from the old node since they are passed on
 	tree.Nodes[indexBracketNode].BracketCounter = 0
 	if indexBracketNode != 0 && tree.Nodes[indexBracketNode-1].Token == Tokens.TokenLambda {
@@ -379,11 +399,11 @@ func (tree *ParseTree) ApplyToClosestNotAppliedApplication(token int, lexeme str
 			tree.Nodes[i].IsInApplication = true
 			tree.Nodes[i].IsSecondInApplication = false
 			// Inject nested application.
-			newNode = Node{Tokens.Application, "Apply", oldDepthAtApplication, false, false, 0}
+			newNode = Node{Tokens.Application, "Apply", oldDepthAtApplication, false, false, 0, Tokens.Span{}}
 			tree.InjectNodeAtIndex(newNode, i)
 			// Add the new node.
 			tree.currentDepth = oldDepthAtApplication + 1
-			newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter}
+			newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter, tree.span}
 			tree.Nodes = append(tree.Nodes, newNode)
 			return
 		} // if
@@ -398,14 +418,14 @@ func (tree *ParseTree) ApplyToClosestNotAppliedApplication(token int, lexeme str
 // bracketsCounter: denotes the amount of opened brackets.
 func (tree *ParseTree) ApplyToPrevNode(token int, lexeme string, bracketsCounter int) {
 	var newNode Node
-	newNode = Node{Tokens.Application, "Apply", tree.currentDepth, false, false, 0}
+	newNode = Node{Tokens.Application, "Apply", tree.currentDepth, false, false, 0, Tokens.Span{}}
 	tree.InjectNodeAtIndex(newNode, len(tree.Nodes)-1)
 	// Set prev node as first in the application.
 	tree.Nodes[len(tree.Nodes)-1].IsInApplication = true
 	tree.Nodes[len(tree.Nodes)-1].IsSecondInApplication = false
 	tree.Nodes[len(tree.Nodes)-1].Depth++
 	tree.currentDepth++
-	newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter}
+	newNode = Node{token, lexeme, tree.currentDepth, true, true, bracketsCounter, tree.span}
 	tree.Nodes = append(tree.Nodes, newNode)
 	return
 }
@@ -417,8 +437,23 @@ func (tree *ParseTree) ClearTree() {
 	tree.currentDepth = 0
 	tree.Nodes = nil
 	tree.IndexDoubleDot = -1
+	tree.Name = nil
+	tree.Context = nil
+	tree.Expression = nil
+	tree.Type = nil
 }
 
+// UpdateSpans
+// Extends the span of every node such that it covers all nodes in its subtree.
+// Called once the whole line is parsed, since nodes are moved around while parsing.
+func (tree *ParseTree) UpdateSpans() {
+	for i := len(tree.Nodes) - 1; i >= 0; i-- {
+		for j := i + 1; j < len(tree.Nodes) && tree.Nodes[j].Depth > tree.Nodes[i].Depth; j++ {
+			tree.Nodes[i].Span = tree.Nodes[i].Span.Join(tree.Nodes[j].Span)
+		}
+	}
+} // UpdateSpans
+
 // FindLambdas
 // returns a slice containing all the indices of the lambdas in the node slice of our AST
 func (tree *ParseTree) FindLambdas() []int {
@@ -608,13 +643,19 @@ func (tree ParseTree) SubTreeToStandardOutput(index int) string {
 				}
 				brackets = adjustBrackets
 			}
-			isInLambda = true
-			ajdustOpenLambda = true
+			// Only a lambda with annotated type has nodes nested underneath its variable.
+			var hasType = i+2 < len(tree.Nodes) && tree.Nodes[i+2].Depth > tree.Nodes[i+1].Depth
+			isInLambda = hasType
+			ajdustOpenLambda = hasType
 			Output += brackets
 			Output += "\\"
 
 			Output += tree.Nodes[i+1].Lexeme
-			Output += "^"
+			if hasType {
+				Output += "^"
+			} else {
+				Output += " "
+			}
 			lastIsUvar = false
 			lastIsVariable = false
 			i++
@@ -0,0 +1,126 @@
+/*
+ * Parser and Lexical Analyser Term.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package parsetree
+
+import (
+	"Parser-TypeChecking/Tokens"
+	"strings"
+)
+
+// Term
+// Tree representation of a parsed expression or type, every Term wraps the Node of its token.
+// The children depend on the token of the node:
+//   - TokenVariable: none, or the annotated type if the variable is bound by a lambda.
+//   - TokenLambda: the bound variable and the body.
+//   - Application: the applied expression and the argument.
+//   - TokenUVar: none.
+//   - TokenFunction: the domain and the codomain.
+//   - Context: a variable for every assumption, the type of the assumption is the child of the variable.
+type Term struct {
+	Node
+	Children []*Term
+}
+
+// NewTerm
+// Creates a term for the provided node and children, the span of the term covers all children.
+// node: Node of the token.
+// children: Sub terms.
+func NewTerm(node Node, children ...*Term) *Term {
+	var term = Term{node, children}
+	for _, child := range children {
+		if child != nil {
+			term.Span = term.Span.Join(child.Span)
+		}
+	}
+	return &term
+}
+
+// NewVariable
+// Creates a leaf term for a <lvar> or <uvar>.
+// token: TokenVariable or TokenUVar.
+// name: Name of the variable.
+// span: Position of the variable in the analysed line.
+func NewVariable(token int, name string, span Tokens.Span) *Term {
+	return &Term{Node{Token: token, Lexeme: name, Span: span}, nil}
+}
+
+// NewApplication
+// Creates the term for applying argument to function.
+func NewApplication(function *Term, argument *Term) *Term {
+	return NewTerm(Node{Token: Tokens.Application, Lexeme: "Apply"}, function, argument)
+}
+
+// NewLambda
+// Creates the term for a lambda with the provided bound variable and body.
+// The annotated type of the lambda is stored as the child of the bound variable.
+// span: Position of the lambda symbol.
+func NewLambda(span Tokens.Span, variable *Term, body *Term) *Term {
+	return NewTerm(Node{Token: Tokens.TokenLambda, Lexeme: "λ", Span: span}, variable, body)
+}
+
+// NewFunction
+// Creates the function type from domain to codomain.
+func NewFunction(domain *Term, codomain *Term) *Term {
+	return NewTerm(Node{Token: Tokens.TokenFunction, Lexeme: "->"}, domain, codomain)
+}
+
+// String
+// Prints the term with as few brackets as possible, application is left associative
+// and the function type right associative.
+func (term *Term) String() string {
+	var output strings.Builder
+	term.write(&output)
+	return output.String()
+}
+
+// write
+// Writes the term to output, used by String.
+func (term *Term) write(output *strings.Builder) {
+	switch term.Token {
+	case Tokens.TokenLambda:
+		var variable = term.Children[0]
+		output.WriteString("\\")
+		output.WriteString(variable.Lexeme)
+		if len(variable.Children) > 0 {
+			output.WriteString("^")
+			writeBracketed(output, variable.Children[0], variable.Children[0].Token == Tokens.TokenFunction)
+		}
+		output.WriteString(" ")
+		term.Children[1].write(output)
+	case Tokens.Application:
+		writeBracketed(output, term.Children[0], term.Children[0].Token == Tokens.TokenLambda)
+		output.WriteString(" ")
+		writeBracketed(output, term.Children[1], term.Children[1].Token == Tokens.TokenLambda ||
+			term.Children[1].Token == Tokens.Application)
+	case Tokens.TokenFunction:
+		writeBracketed(output, term.Children[0], term.Children[0].Token == Tokens.TokenFunction)
+		output.WriteString(" -> ")
+		term.Children[1].write(output)
+	case Tokens.Context:
+		for i, variable := range term.Children {
+			if i > 0 {
+				output.WriteString(", ")
+			}
+			output.WriteString(variable.Lexeme)
+			output.WriteString(" : ")
+			variable.Children[0].write(output)
+		}
+	default:
+		output.WriteString(term.Lexeme)
+	}
+}
+
+// writeBracketed
+// Writes the term to output, surrounded by brackets if needed.
+func writeBracketed(output *strings.Builder, term *Term, needsBrackets bool) {
+	if needsBrackets {
+		output.WriteString("(")
+		term.write(output)
+		output.WriteString(")")
+	} else {
+		term.write(output)
+	}
+}
@@ -0,0 +1,37 @@
+/*
+ * Parser and Lexical Analyser Span.go
+ * Copyright (C) 2021-2023 Bas Blokzijl, Leiden, The Netherlands.
+ */
+
+package Tokens
+
+// Span
+// Denotes the position of a token in the analysed line.
+type Span struct {
+	// Line number in the input file, starting at 1, 0 if the line was not read from a file.
+	Line int
+	// Start rune offset of the first character in the analysed input, which may consist of several lines.
+	Start int
+	// End rune offset directly after the last character.
+	End int
+}
+
+// Join
+// Returns the smallest span that covers both spans.
+// other: The span to join with.
+func (span Span) Join(other Span) Span {
+	if span.End <= span.Start {
+		return other
+	}
+	if other.End <= other.Start {
+		return span
+	}
+	if other.Start < span.Start {
+		span.Line = other.Line
+		span.Start = other.Start
+	}
+	if other.End > span.End {
+		span.End = other.End
+	}
+	return span
+}
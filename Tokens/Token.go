@@ -21,4 +21,48 @@ const (
 	SyntaxError
 	// Application For the concatenation of two expressions.
 	Application
+	// TokenComma Separates the assumptions in a context.
+	TokenComma
+	// TokenTurnstile Separates the context from the judgement, "|-" or '⊢'.
+	TokenTurnstile
+	// Context For the list of assumptions in front of a judgement.
+	Context
+	// TokenDefinition The keyword "def" that starts a definition.
+	TokenDefinition
+	// TokenEquals Separates the name of a definition from its expression.
+	TokenEquals
+	// TokenImport The keyword "import" that starts an import of another file.
+	TokenImport
 )
+
+// Names of the tokens as used in machine readable output, e.g. JSON.
+var Names = map[int]string{
+	TokenVariable:     "Variable",
+	TokenLambda:       "Lambda",
+	TokenLeftBracket:  "LeftBracket",
+	TokenRightBracket: "RightBracket",
+	TokenFunction:     "Function",
+	TypeSymbol:        "TypeSymbol",
+	TokenUVar:         "UVar",
+	TokenDoubleDot:    "DoubleDot",
+	LexicalEndOfLine:  "EndOfLine",
+	SyntaxError:       "SyntaxError",
+	Application:       "Application",
+	TokenComma:        "Comma",
+	TokenTurnstile:    "Turnstile",
+	Context:           "Context",
+	TokenDefinition:   "Definition",
+	TokenEquals:       "Equals",
+	TokenImport:       "Import",
+}
+
+// Token
+// A token found by the LexicalAnalyser.
+type Token struct {
+	// Internal code of the token, see the constants above.
+	Kind int
+	// Characters of the token as they occur in the line.
+	Lexeme string
+	// Position of the token in the line.
+	Span Span
+}
@@ -0,0 +1,180 @@
+/*
+ * Parser and Lexical Analyser Derivation.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package TypeChecker
+
+import (
+	"Parser-TypeChecking/Globals"
+	"Parser-TypeChecking/Parsetree"
+	"Parser-TypeChecking/Tokens"
+	"Parser-TypeChecking/Types"
+	"strings"
+)
+
+// RuleNames contains the name of every rule as printed in a derivation.
+var RuleNames = map[int]string{
+	VariableRule:    "Var",
+	ApplicationRule: "App",
+	LamdbaRule:      "Lambda",
+	DefinitionRule:  "Def",
+}
+
+/* Derivation
+ * Typing derivation of an expression, the conclusion Context |- Expression : Type
+ * follows from the premises by Rule.
+ */
+type Derivation struct {
+	// Rule that was applied, one of VariableRule, ApplicationRule, LamdbaRule or DefinitionRule.
+	Rule int
+	// Variables with their type in scope of the expression.
+	Context []Globals.VariableType
+	// The typed (sub)expression.
+	Expression *parsetree.Term
+	// Type found for the expression.
+	Type *Types.Type
+	// Derivations of the subexpressions, none for VariableRule and DefinitionRule.
+	Premises []*Derivation
+}
+
+/* newDerivation
+ * Creates the conclusion of a rule, the context is copied from variables.
+ */
+func newDerivation(rule int, variables *Globals.Vars, expression *parsetree.Term, t *Types.Type,
+	premises ...*Derivation) *Derivation {
+	return &Derivation{rule, variables.Context.Entries(), expression, t, premises}
+}
+
+/* principal
+ * Resolves all types in the derivation and renames their type variables to a, b, c, ...
+ * The type of the conclusion is renamed first, so it is the same as the principal type.
+ * names: Type variables renamed so far.
+ */
+func (derivation *Derivation) principal(inference *Inference, names map[string]*Types.Type) {
+	derivation.Type = rename(inference.Apply(derivation.Type), names)
+	for i, assumption := range derivation.Context {
+		derivation.Context[i].Type = rename(inference.Apply(assumption.Type), names)
+	}
+	for _, premise := range derivation.Premises {
+		premise.principal(inference, names)
+	}
+}
+
+/* ASCII
+ * Prints the derivation as a tree with the conclusion on top, every premise is indented below its conclusion.
+ * The applied rule is printed behind every judgement.
+ */
+func (derivation *Derivation) ASCII() string {
+	var output strings.Builder
+	derivation.writeASCII(&output, "")
+	return output.String()
+}
+
+/* writeASCII
+ * Writes the derivation to output, every line starts with indent.
+ */
+func (derivation *Derivation) writeASCII(output *strings.Builder, indent string) {
+	output.WriteString(indent)
+	if len(derivation.Context) > 0 {
+		output.WriteString(contextString(derivation.Context, func(t *Types.Type) string { return t.String() }))
+		output.WriteString(" ")
+	}
+	output.WriteString("|- " + derivation.Expression.String() + " : " + derivation.Type.String())
+	output.WriteString("   (" + RuleNames[derivation.Rule] + ")\n")
+	for _, premise := range derivation.Premises {
+		premise.writeASCII(output, indent+"    ")
+	}
+}
+
+/* contextString
+ * Prints the assumptions separated by commas, typeString prints a single type.
+ */
+func contextString(context []Globals.VariableType, typeString func(*Types.Type) string) string {
+	var assumptions = make([]string, len(context))
+	for i, assumption := range context {
+		assumptions[i] = assumption.VarName + " : " + typeString(assumption.Type)
+	}
+	return strings.Join(assumptions, ", ")
+}
+
+/* LaTeX
+ * Prints the derivation as a prooftree of the bussproofs package.
+ */
+func (derivation *Derivation) LaTeX() string {
+	var output strings.Builder
+	output.WriteString("\\begin{prooftree}\n")
+	derivation.writeLaTeX(&output)
+	output.WriteString("\\end{prooftree}\n")
+	return output.String()
+}
+
+/* LaTeXDocument
+ * Prints a complete LaTeX document containing a prooftree for every derivation.
+ */
+func LaTeXDocument(derivations []*Derivation) string {
+	var output strings.Builder
+	output.WriteString("\\documentclass{article}\n\\usepackage{bussproofs}\n\\begin{document}\n")
+	for _, derivation := range derivations {
+		output.WriteString(derivation.LaTeX())
+	}
+	output.WriteString("\\end{document}\n")
+	return output.String()
+}
+
+/* writeLaTeX
+ * Writes the premises followed by the inference of the conclusion, bussproofs reads the proof bottom up.
+ */
+func (derivation *Derivation) writeLaTeX(output *strings.Builder) {
+	if len(derivation.Premises) == 0 {
+		output.WriteString("\\AxiomC{}\n")
+	}
+	for _, premise := range derivation.Premises {
+		premise.writeLaTeX(output)
+	}
+	output.WriteString("\\RightLabel{\\scriptsize " + RuleNames[derivation.Rule] + "}\n")
+	switch len(derivation.Premises) {
+	case 2:
+		output.WriteString("\\BinaryInfC{$")
+	default:
+		output.WriteString("\\UnaryInfC{$")
+	}
+	if len(derivation.Context) > 0 {
+		output.WriteString(contextString(derivation.Context, latexType) + " ")
+	}
+	output.WriteString("\\vdash " + latexExpression(derivation.Expression) + " : " + latexType(derivation.Type))
+	output.WriteString("$}\n")
+}
+
+/* latexType
+ * Prints the type for math mode.
+ */
+func latexType(t *Types.Type) string {
+	return strings.ReplaceAll(t.String(), "->", "\\to")
+}
+
+/* latexExpression
+ * Prints the expression for math mode, e.g. \lambda x^{A}.\, x y.
+ */
+func latexExpression(expression *parsetree.Term) string {
+	switch expression.Token {
+	case Tokens.TokenLambda:
+		var variable = expression.Children[0]
+		var binder = "\\lambda " + variable.Lexeme
+		if len(variable.Children) > 0 {
+			binder += "^{" + strings.ReplaceAll(variable.Children[0].String(), "->", "\\to") + "}"
+		}
+		return binder + ".\\, " + latexExpression(expression.Children[1])
+	case Tokens.Application:
+		var function, argument = expression.Children[0], expression.Children[1]
+		var left, right = latexExpression(function), latexExpression(argument)
+		if function.Token == Tokens.TokenLambda {
+			left = "(" + left + ")"
+		}
+		if argument.Token == Tokens.TokenLambda || argument.Token == Tokens.Application {
+			right = "(" + right + ")"
+		}
+		return left + "\\; " + right
+	}
+	return expression.Lexeme
+}
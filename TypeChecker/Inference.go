@@ -0,0 +1,228 @@
+/*
+ * Parser and Lexical Analyser Inference.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package TypeChecker
+
+import (
+	"Parser-TypeChecking/Types"
+	"strconv"
+)
+
+/* Inference
+ * Constraint solver used to infer the types of lambdas without annotation.
+ * Every such lambda gets a fresh type variable, the constraints found by the type rules
+ * are solved immediately by unification, the solution is stored as a substitution.
+ */
+type Inference struct {
+	// Solved type variables, maps the name of a variable to its type.
+	substitution map[string]*Types.Type
+	// Amount of fresh type variables handed out.
+	fresh int
+}
+
+/* occursError
+ * Returned by unify if a type variable would have to contain itself.
+ */
+type occursError struct {
+	variable *Types.Type
+	other    *Types.Type
+}
+
+/* Error
+ * Describes the infinite type.
+ */
+func (err *occursError) Error() string {
+	return describe(err, (*Types.Type).String)
+}
+
+/* mismatchError
+ * Returned by unify if two types have a different structure.
+ */
+type mismatchError struct {
+	left  *Types.Type
+	right *Types.Type
+}
+
+/* Error
+ * Describes the types that differ.
+ */
+func (err *mismatchError) Error() string {
+	return describe(err, (*Types.Type).String)
+}
+
+/* describe
+ * Describes an error returned by unify, every type in the description is printed by show.
+ * err: The error returned by unify.
+ * show: Prints a type, e.g. the method of Readable.
+ */
+func describe(err error, show func(*Types.Type) string) string {
+	switch err := err.(type) {
+	case *occursError:
+		return "cannot construct infinite type " + show(err.variable) + " = " + show(err.other)
+	case *mismatchError:
+		return "cannot unify " + show(err.left) + " with " + show(err.right)
+	}
+	return err.Error()
+}
+
+/* NewInference
+ * Creates a solver without any solved type variables.
+ */
+func NewInference() *Inference {
+	return &Inference{substitution: map[string]*Types.Type{}}
+}
+
+/* Fresh
+ * Returns a type variable that was not used before by this solver.
+ */
+func (inference *Inference) Fresh() *Types.Type {
+	inference.fresh++
+	return Types.NewVariable("t" + strconv.Itoa(inference.fresh))
+}
+
+/* Apply
+ * Replaces all solved type variables in t by their solution.
+ * t: The type to resolve.
+ */
+func (inference *Inference) Apply(t *Types.Type) *Types.Type {
+	switch t.Kind {
+	case Types.Variable:
+		if solution, found := inference.substitution[t.Name]; found {
+			return inference.Apply(solution)
+		}
+		return t
+	case Types.Arrow:
+		return Types.NewArrow(inference.Apply(t.Domain), inference.Apply(t.Codomain))
+	}
+	return t
+}
+
+/* occurs
+ * Returns whether the type variable occurs in t, t has to be resolved.
+ */
+func occurs(variable *Types.Type, t *Types.Type) bool {
+	switch t.Kind {
+	case Types.Variable:
+		return t.Name == variable.Name
+	case Types.Arrow:
+		return occurs(variable, t.Domain) || occurs(variable, t.Codomain)
+	}
+	return false
+}
+
+/* Unify
+ * Solves the constraint left = right by extending the substitution.
+ * Returns an error if the types cannot be made equal, the substitution is then left as it was.
+ * left: Type on the left of the constraint.
+ * right: Type on the right of the constraint.
+ */
+func (inference *Inference) Unify(left *Types.Type, right *Types.Type) error {
+	var solved = make(map[string]*Types.Type, len(inference.substitution))
+	for name, solution := range inference.substitution {
+		solved[name] = solution
+	}
+	if err := inference.unify(left, right); err != nil {
+		inference.substitution = solved
+		return err
+	}
+	return nil
+}
+
+/* unify
+ * Solves left = right, see Unify.
+ */
+func (inference *Inference) unify(left *Types.Type, right *Types.Type) error {
+	left = inference.Apply(left)
+	right = inference.Apply(right)
+	if left.Kind != Types.Variable && right.Kind == Types.Variable {
+		left, right = right, left
+	}
+	switch {
+	case left.Kind == Types.Variable:
+		if right.Kind == Types.Variable && right.Name == left.Name {
+			return nil
+		}
+		// Occurs check, a -> a = a has no finite solution.
+		if occurs(left, right) {
+			return &occursError{left, right}
+		}
+		inference.substitution[left.Name] = right
+		return nil
+	case left.Kind == Types.Arrow && right.Kind == Types.Arrow:
+		if err := inference.unify(left.Domain, right.Domain); err != nil {
+			return err
+		}
+		return inference.unify(left.Codomain, right.Codomain)
+	case left.Kind == Types.Base && right.Kind == Types.Base && left.Name == right.Name:
+		return nil
+	}
+	return &mismatchError{left, right}
+}
+
+/* Readable
+ * Returns a function that prints a type with its type variables resolved and renamed to a, b, c, ... in order of
+ * appearance, the same as in the principal type of a derivation. The names are shared by all types printed by the
+ * function, so a variable that occurs in several types of an error message gets the same name in all of them.
+ */
+func (inference *Inference) Readable() func(*Types.Type) string {
+	var names = map[string]*Types.Type{}
+	return func(t *Types.Type) string {
+		return rename(inference.Apply(t), names).String()
+	}
+}
+
+/* Instantiate
+ * Replaces every type variable in t by a fresh type variable, every occurrence of a variable by the same one.
+ * Used for the type of a definition, which may be used at a different type on every use.
+ * t: The type of the definition.
+ */
+func (inference *Inference) Instantiate(t *Types.Type) *Types.Type {
+	return inference.instantiate(t, map[string]*Types.Type{})
+}
+
+/* instantiate
+ * Replaces the type variables in t, see Instantiate, fresh contains the variables replaced so far.
+ */
+func (inference *Inference) instantiate(t *Types.Type, fresh map[string]*Types.Type) *Types.Type {
+	switch t.Kind {
+	case Types.Variable:
+		if _, found := fresh[t.Name]; !found {
+			fresh[t.Name] = inference.Fresh()
+		}
+		return fresh[t.Name]
+	case Types.Arrow:
+		var domain = inference.instantiate(t.Domain, fresh)
+		return Types.NewArrow(domain, inference.instantiate(t.Codomain, fresh))
+	}
+	return t
+}
+
+/* rename
+ * Renames the type variables in t, names contains the variables renamed so far.
+ */
+func rename(t *Types.Type, names map[string]*Types.Type) *Types.Type {
+	switch t.Kind {
+	case Types.Variable:
+		if _, found := names[t.Name]; !found {
+			names[t.Name] = Types.NewVariable(variableName(len(names)))
+		}
+		return names[t.Name]
+	case Types.Arrow:
+		var domain = rename(t.Domain, names)
+		return Types.NewArrow(domain, rename(t.Codomain, names))
+	}
+	return t
+}
+
+/* variableName
+ * Returns the name of the index-th type variable: a, ..., z, a1, ..., z1, a2, ...
+ */
+func variableName(index int) string {
+	var name = string(rune('a' + index%26))
+	if index >= 26 {
+		name += strconv.Itoa(index / 26)
+	}
+	return name
+}
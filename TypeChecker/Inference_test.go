@@ -0,0 +1,110 @@
+/*
+ * Parser and Lexical Analyser Inference_test.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package TypeChecker
+
+import (
+	"Parser-TypeChecking/Parser"
+	"Parser-TypeChecking/Types"
+	"errors"
+	"strings"
+	"testing"
+)
+
+/* TestUnify
+ * Unifies pairs of types and compares the solved left type, or the error if they cannot be unified.
+ */
+func TestUnify(t *testing.T) {
+	var a, b = Types.NewVariable("a"), Types.NewVariable("b")
+	var tests = []struct {
+		name  string
+		left  *Types.Type
+		right *Types.Type
+		// The left type after unification, empty if an error is expected.
+		want string
+		// The error, empty if the types unify.
+		err string
+	}{
+		{"variable with base", a, Types.NewBase("A"), "A", ""},
+		{"arrows", Types.NewArrow(a, b), Types.NewArrow(Types.NewBase("A"), a), "A -> A", ""},
+		{"different bases", Types.NewBase("A"), Types.NewBase("B"), "", "cannot unify A with B"},
+		{"base with arrow", Types.NewBase("A"), Types.NewArrow(a, b), "", "cannot unify A with a -> b"},
+		{"occurs check", a, Types.NewArrow(a, b), "", "cannot construct infinite type a = a -> b"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var inference = NewInference()
+			var err = inference.Unify(test.left, test.right)
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Fatalf("Unify(%s, %s) = %v, want %q", test.left, test.right, err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unify(%s, %s) = %v", test.left, test.right, err)
+			}
+			if got := inference.Apply(test.left).String(); got != test.want {
+				t.Errorf("Unify(%s, %s) solved the left type to %s, want %s", test.left, test.right, got, test.want)
+			}
+		})
+	}
+}
+
+/* TestSynthesize
+ * Synthesizes the principal type of expressions without annotations.
+ */
+func TestSynthesize(t *testing.T) {
+	var tests = []struct {
+		expression string
+		// The principal type, empty if an error is expected.
+		want string
+		// Part of the error, empty if the expression can be typed.
+		err string
+	}{
+		{`\x x`, "a -> a", ""},
+		{`\x \y x`, "a -> b -> a", ""},
+		{`\f \g \x f (g x)`, "(a -> b) -> (c -> a) -> c -> b", ""},
+		{`\x^A \y^B x y`, "", "E1 should find a function type, found A"},
+		{`\x x x`, "", "cannot construct infinite type a = a -> b"},
+	}
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			var judgement, err = parser.ParseWith(test.expression, parser.Options{TypeOptional: true})
+			if err != nil {
+				t.Fatalf("ParseWith(%q) = %v", test.expression, err)
+			}
+			var derivation, typeErr = SynthesizeJudgement(judgement)
+			if test.err != "" {
+				if typeErr == nil || !strings.Contains(typeErr.Error(), test.err) {
+					t.Fatalf("SynthesizeJudgement(%q) = %v, want an error containing %q", test.expression, typeErr, test.err)
+				}
+				return
+			}
+			if typeErr != nil {
+				t.Fatalf("SynthesizeJudgement(%q) = %v", test.expression, typeErr)
+			}
+			if got := derivation.Type.String(); got != test.want {
+				t.Errorf("SynthesizeJudgement(%q) = %s, want %s", test.expression, got, test.want)
+			}
+		})
+	}
+}
+
+/* TestOccursCheckLeavesSubstitution
+ * A failed unification does not solve any type variable.
+ */
+func TestOccursCheckLeavesSubstitution(t *testing.T) {
+	var inference = NewInference()
+	var a, b = inference.Fresh(), inference.Fresh()
+	var err = inference.Unify(Types.NewArrow(b, a), Types.NewArrow(Types.NewBase("A"), Types.NewArrow(a, a)))
+	var occurs *occursError
+	if !errors.As(err, &occurs) {
+		t.Fatalf("Unify = %v, want an occurs check failure", err)
+	}
+	if got := inference.Apply(b); !got.Equal(b) {
+		t.Errorf("Unify solved %s to %s after failing", b, got)
+	}
+}
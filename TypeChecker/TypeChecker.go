@@ -6,154 +6,316 @@
 package TypeChecker
 
 import (
-    "Parser-TypeChecking/Globals"
-    "fmt"
-    "os"
-    "unicode"
+	"Parser-TypeChecking/Errors"
+	"Parser-TypeChecking/Globals"
+	"Parser-TypeChecking/Parsetree"
+	"Parser-TypeChecking/Tokens"
+	"Parser-TypeChecking/Types"
 )
 
-/* calcParts
- * Uses the standard-output on ParseTree to get the final slices used for typechecking
- * variables: passed on arguments containing the TreeOutput.
- * expressionSlice: by-reference, contains the expression.
- * typeSLice: by-reference, contains the types.
- */
-func calcParts(variables *Globals.Vars, expressionSlice *string, typeSlice *string) {
-    *expressionSlice = variables.Tree.SubTreeToStandardOutput(1)
-    *typeSlice = variables.Tree.SubTreeToStandardOutput(variables.Tree.IndexDoubleDot)
-}
-
 const (
 	VariableRule = iota
-	ApplicationRule 
-	LamdbaRule 
+	ApplicationRule
+	LamdbaRule
+	// DefinitionRule For a variable that refers to a definition in the environment.
+	DefinitionRule
 )
 
-/* findE
- * Finds the part where the brackets close and returns encapsulated Expression.
- * expressionSlice: The analysed expression.
- * index: Index in the expression at which the opening bracket occurred.
- * firstE: Whether this the first expression.
- */
-func findE(expressionSlice string, index int, firstE bool) string {
-    word := ""
-    var currLetter byte
-    countbrack := 1
-    for i := index; i < len(expressionSlice); i++ {
-        currLetter = expressionSlice[i]
-        if currLetter == '(' {
-            countbrack++
-        } else if currLetter == ')' || (firstE && currLetter == ' '){
-            countbrack--
-        }
-        if countbrack == 0 {
-            break
-        }
-        word +=  string(currLetter)
-    }
-    return word
+/* findRule
+ * Decide which rule needs to be applied next to find the type for the expression.
+ * expression: The (sub)expression to type.
+ */
+func findRule(expression *parsetree.Term) int {
+	switch expression.Token {
+	case Tokens.TokenLambda:
+		return LamdbaRule
+	case Tokens.Application:
+		return ApplicationRule
+	default:
+		return VariableRule
+	}
 }
 
-/* findWord
- * uses the first provided index of the word to return entire word as string, which stopped at first non-letter
- * expressionSlice: Part of the total expression, contains the word we are looking for.
- * index: Index at which the word starts in the provided string.
- */
-func findWord(expressionSlice string, index int) string{
-    word := ""
-    // walk until end of the expression.
-    for _, r := range expressionSlice[index:] {
-        if !unicode.IsLetter(r) {
-            return word
-        }
-         word += string(r)
-     }
-     return word
+/* typeError
+ * Creates a TypeError that points at the provided subterm.
+ * term: The subterm that could not be typed.
+ * message: Description of the error.
+ */
+func typeError(term *parsetree.Term, message string) error {
+	return &Errors.TypeError{Message: message, Span: term.Span}
 }
 
+/* FindType
+ * Finds the principal type for given expression, using context in variables.
+ * Lambdas without annotated type get a fresh type variable, which is solved by unification.
+ * Returns the synthesized type, or a TypeError that points at the subterm that could not be typed.
+ * variables: Contains expression context.
+ * expression: The (sub)expression to type.
+ */
+func FindType(variables *Globals.Vars, expression *parsetree.Term) (*Types.Type, error) {
+	var derivation, err = FindDerivation(variables, expression)
+	if err != nil {
+		return nil, err
+	}
+	return derivation.Type, nil
+}
 
-/* findRule
- * Decide which rule needs to be applied next to find the type for the expression.
- * expressionSlice: The total expression.
- */
-func findRule(expressionSlice string) int{
-    if len(expressionSlice) >=1 && expressionSlice[0] == '(' {
-        if len(expressionSlice) >=2 && expressionSlice[1] == '\\' { 
-            return LamdbaRule
-        } else {
-            return ApplicationRule
-        }
-    } else {
-        return VariableRule
-    }
+/* FindDerivation
+ * Same as FindType, but returns the whole derivation of the principal type.
+ * variables: Contains expression context.
+ * expression: The (sub)expression to type.
+ */
+func FindDerivation(variables *Globals.Vars, expression *parsetree.Term) (*Derivation, error) {
+	var inference = NewInference()
+	var derivation, err = findType(variables, inference, expression)
+	if err != nil {
+		return nil, err
+	}
+	derivation.principal(inference, map[string]*Types.Type{})
+	return derivation, nil
 }
 
 /* findType
- * Finds a unique type for given expression, using context in variables.
- * Recursive function that slices up the expression slice until it is a variable.
+ * Recursive function that walks the expression tree until it is a variable, see FindType.
+ * Returns the derivation of the expression, its types may contain type variables that are solved in inference.
  * variables: Contains expression context.
- * expressionSlice: The entire expression.
- */
-func findType(variables *Globals.Vars, expressionSlice string) string{
-    T1 := ""
-    T2 := ""
-    E1 := ""
-    E2 := ""
-    foundVar := ""
-    word := ""
-    switch findRule(expressionSlice) {
-    case VariableRule:
-
-        word = variables.Context.GetLast(expressionSlice) 
-        if variables.Context.FindInList (expressionSlice) == -1 {
-            fmt.Fprintf(os.Stderr, "%s\n", "Typecheck error: Variable not in context")
-            os.Exit(1)
-        }
-        word = variables.Context.GetLast(expressionSlice)
-        break
-    case ApplicationRule:
-        // Break expression apart and find type of the parts.
-        E1 = findE(expressionSlice, 1, true)
-        E2 = findE(expressionSlice, 2 + len(E1), false)
-        // Recursive.
-        T1 = findType(variables, E1)
-        T2 = findType(variables, E2)
-        if T1[:(len(T2) + 3)] != (T2 + "->"){
-            fmt.Fprintf(os.Stderr, "%s\n", "Typecheck error: E1 should find a function type")
-        }
-        if T2 != T1[:len(T2)] { 
-            fmt.Fprintf(os.Stderr, "%s\n", "Typecheck error: codomain E1 not the same as type of E2")
-        }
-        word = T1[(len(T2) + 3):(len(T1)-1)]
-        break
-    case LamdbaRule:
-        // Add lambda type to the type that is applied and find type of applied term.
-        foundVar = findWord(expressionSlice, 2)
-        T1 = findE(expressionSlice, 4+len(foundVar), false)
-        // Add variable to context.
-        variables.Context.AddVarType(foundVar, T1)
-        E1 = findE(expressionSlice, 5+len(foundVar)+len(T1), false)
-        T2 = findType(variables, E1)
-            word = "(" + T1 + "->"+ T2 + ")"
-    default:
-        break
-    }
-    return word
+ * inference: Solver for the type variables.
+ * expression: The (sub)expression to type.
+ */
+func findType(variables *Globals.Vars, inference *Inference, expression *parsetree.Term) (*Derivation, error) {
+	var rule = findRule(expression)
+	switch rule {
+	case VariableRule:
+		var found = variables.Context.GetLast(expression.Lexeme)
+		if found != nil {
+			return newDerivation(rule, variables, expression, found), nil
+		}
+		// Variables in the context shadow definitions.
+		if definition := variables.Environment.Lookup(expression.Lexeme); definition != nil {
+			return newDerivation(DefinitionRule, variables, expression, inference.Instantiate(definition.Type)), nil
+		}
+		return nil, typeError(expression, "Variable not in context")
+	case ApplicationRule:
+		// Find type of the parts, E1 has to be a function that accepts E2.
+		var E1, E2 = expression.Children[0], expression.Children[1]
+		D1, err := findType(variables, inference, E1)
+		if err != nil {
+			return nil, err
+		}
+		D2, err := findType(variables, inference, E2)
+		if err != nil {
+			return nil, err
+		}
+		var T1, T2 = inference.Apply(D1.Type), D2.Type
+		if T1.Kind == Types.Variable {
+			// E1 is not known to be a function yet, it has to become one.
+			var codomain = inference.Fresh()
+			if err = inference.Unify(T1, Types.NewArrow(T2, codomain)); err != nil {
+				return nil, typeError(expression, "Cannot apply E1 to E2: "+describe(err, inference.Readable()))
+			}
+			return newDerivation(rule, variables, expression, codomain, D1, D2), nil
+		}
+		if T1.Kind != Types.Arrow {
+			return nil, typeError(E1, "E1 should find a function type, found "+inference.Readable()(T1))
+		}
+		if err = inference.Unify(T1.Domain, T2); err != nil {
+			// Types in the message are named the same as in -synth, a, b, c, ...
+			var show = inference.Readable()
+			var message = "domain E1 (" + show(T1.Domain) + ") not the same as type of E2 (" + show(T2) + ")"
+			if _, isOccurs := err.(*occursError); isOccurs {
+				message += ": " + describe(err, show)
+			}
+			return nil, typeError(E2, message)
+		}
+		return newDerivation(rule, variables, expression, T1.Codomain, D1, D2), nil
+	case LamdbaRule:
+		// Add lambda type to the context and find type of the body.
+		var variable, body = expression.Children[0], expression.Children[1]
+		var T1 *Types.Type
+		if len(variable.Children) == 0 {
+			// No annotation, the type is inferred.
+			T1 = inference.Fresh()
+		} else {
+			var err error
+			if T1, err = Types.FromTerm(variable.Children[0]); err != nil {
+				return nil, err
+			}
+		}
+		var conclusion = newDerivation(rule, variables, expression, nil)
+		// The variable is only in scope in the body, it shadows earlier variables with the same name.
+		defer variables.Context.Truncate(variables.Context.Len())
+		variables.Context.AddVarType(variable.Lexeme, T1)
+		D2, err := findType(variables, inference, body)
+		if err != nil {
+			return nil, err
+		}
+		conclusion.Type = Types.NewArrow(T1, D2.Type)
+		conclusion.Premises = []*Derivation{D2}
+		return conclusion, nil
+	}
+	return nil, typeError(expression, "unknown expression")
+}
+
+/* SeedContext
+ * Adds the assumptions of the context of the judgement to the context in variables.
+ * Returns a TypeError if a variable is assumed twice.
+ * variables: Provides context.
+ */
+func SeedContext(variables *Globals.Vars) error {
+	if variables.Tree.Context == nil {
+		return nil
+	}
+	for i, variable := range variables.Tree.Context.Children {
+		for _, previous := range variables.Tree.Context.Children[:i] {
+			if previous.Lexeme == variable.Lexeme {
+				return typeError(variable, "Variable "+variable.Lexeme+" occurs twice in context")
+			}
+		}
+		var assumed, err = Types.FromTerm(variable.Children[0])
+		if err != nil {
+			return err
+		}
+		variables.Context.AddVarType(variable.Lexeme, assumed)
+	}
+	return nil
+}
+
+/* Synthesize
+ * Uses FindType() recursively on the expression part of the line, in the context of the line,
+ * and returns the synthesized type. The type part of the line, if any, is ignored.
+ * variables: Provides context.
+ */
+func Synthesize(variables *Globals.Vars) (*Types.Type, error) {
+	var derivation, err = SynthesizeDerivation(variables)
+	if err != nil {
+		return nil, err
+	}
+	return derivation.Type, nil
 }
 
-/* TypeCheker
- * Uses findType() recursively on the expression part of the judgement and finds whether
- * the found type is the same as given type.
+/* SynthesizeDerivation
+ * Same as Synthesize, but returns the whole derivation of the synthesized type.
+ * The assumptions of the judgement are only in scope while typing its expression,
+ * afterwards the context in variables is the same as before.
  * variables: Provides context.
  */
-func TypeChecker(variables *Globals.Vars) {
-    var finalExpression, finalType string
-    calcParts(variables, &finalExpression, &finalType)
-    
-    //does not account for redundant brackets
-    if finalType == findType(variables, finalExpression){
-        fmt.Println("Type checks out")
-    } else {
-        fmt.Println("Does not type check")
-    }
+func SynthesizeDerivation(variables *Globals.Vars) (*Derivation, error) {
+	defer variables.Context.Truncate(variables.Context.Len())
+	if err := SeedContext(variables); err != nil {
+		return nil, err
+	}
+	return FindDerivation(variables, variables.Tree.Expression)
+}
+
+/* Check
+ * Synthesizes the type of the expression part of the judgement, in the context of the judgement,
+ * and decides whether the given type is an instance of it.
+ * Returns the derivation of the synthesized type and the verdict, or a TypeError if the expression
+ * cannot be typed at all.
+ * variables: Provides context.
+ */
+func Check(variables *Globals.Vars) (*Derivation, bool, error) {
+	var expectedType, err = Types.FromTerm(variables.Tree.Type)
+	if err != nil {
+		return nil, false, err
+	}
+	derivation, err := SynthesizeDerivation(variables)
+	if err != nil {
+		return nil, false, err
+	}
+	// Types are compared structurally, hence redundant brackets do not matter.
+	// The given type may be an instance of the principal type, e.g. A -> A for a -> a.
+	return derivation, NewInference().Unify(derivation.Type, expectedType) == nil, nil
+}
+
+/* judgementVars
+ * Returns fresh variables containing the parsed judgement, typed in the provided context.
+ * context: Assumptions in scope of the judgement, nil for an empty context.
+ * environment: Definitions in scope of the judgement, nil for none.
+ */
+func judgementVars(judgement *parsetree.Judgement, context *Globals.VarTypeList, environment *Globals.Environment) *Globals.Vars {
+	var variables = new(Globals.Vars)
+	variables.Environment = environment
+	if context != nil {
+		for _, assumption := range context.Entries() {
+			variables.Context.AddVarType(assumption.VarName, assumption.Type)
+		}
+	}
+	variables.Tree.Context = judgement.Context
+	variables.Tree.Expression = judgement.Expression
+	variables.Tree.Type = judgement.Type
+	return variables
+}
+
+/* SynthesizeJudgement
+ * Same as SynthesizeDerivation, for a judgement obtained from parser.Parse.
+ * The judgement starts from an empty context, only its own assumptions are in scope.
+ * Every call uses its own context, hence it can be called from multiple goroutines.
+ * judgement: The parsed line.
+ */
+func SynthesizeJudgement(judgement *parsetree.Judgement) (*Derivation, error) {
+	return SynthesizeDerivation(judgementVars(judgement, nil, nil))
+}
+
+/* SynthesizeJudgementIn
+ * Same as SynthesizeJudgement, but the judgement starts from the provided context and definitions.
+ * The assumptions of the judgement shadow the provided ones, context itself is not changed.
+ * judgement: The parsed line.
+ * context: Assumptions in scope of the judgement, nil for none.
+ * environment: Definitions in scope of the judgement, nil for none.
+ */
+func SynthesizeJudgementIn(judgement *parsetree.Judgement, context *Globals.VarTypeList, environment *Globals.Environment) (*Derivation, error) {
+	return SynthesizeDerivation(judgementVars(judgement, context, environment))
+}
+
+/* CheckJudgement
+ * Same as Check, for a judgement obtained from parser.Parse.
+ * The judgement starts from an empty context, only its own assumptions are in scope.
+ * Every call uses its own context, hence it can be called from multiple goroutines.
+ * judgement: The parsed line, it has to contain a type.
+ */
+func CheckJudgement(judgement *parsetree.Judgement) (*Derivation, bool, error) {
+	return Check(judgementVars(judgement, nil, nil))
+}
+
+/* CheckJudgementIn
+ * Same as CheckJudgement, but the judgement starts from the provided context and definitions.
+ * The assumptions of the judgement shadow the provided ones, context itself is not changed.
+ * judgement: The parsed line, it has to contain a type.
+ * context: Assumptions in scope of the judgement, nil for none.
+ * environment: Definitions in scope of the judgement, nil for none.
+ */
+func CheckJudgementIn(judgement *parsetree.Judgement, context *Globals.VarTypeList, environment *Globals.Environment) (*Derivation, bool, error) {
+	return Check(judgementVars(judgement, context, environment))
+}
+
+/* DefineJudgement
+ * Types the expression of a definition obtained from parser.Parse and adds it to the environment under its name,
+ * the definition can use the definitions that are already in the environment. If the definition has a type,
+ * it has to be an instance of the synthesized type and becomes the type of the definition.
+ * Returns the derivation of the synthesized type and whether the given type checks out, the definition is only
+ * added if it does. Returns a TypeError if the name is defined already or the expression cannot be typed.
+ * judgement: The parsed definition.
+ * environment: Definitions in scope of the definition, the definition is added to it.
+ */
+func DefineJudgement(judgement *parsetree.Judgement, environment *Globals.Environment) (*Derivation, bool, error) {
+	var name = judgement.Name
+	if environment.Lookup(name.Lexeme) != nil {
+		return nil, false, typeError(name, name.Lexeme+" is defined already")
+	}
+	var derivation, err = SynthesizeDerivation(judgementVars(judgement, nil, environment))
+	if err != nil {
+		return nil, false, err
+	}
+	var definedType = derivation.Type
+	if judgement.Type != nil {
+		if definedType, err = Types.FromTerm(judgement.Type); err != nil {
+			return nil, false, err
+		}
+		if NewInference().Unify(derivation.Type, definedType) != nil {
+			return derivation, false, nil
+		}
+	}
+	environment.Define(name, judgement.Expression, definedType)
+	return derivation, true, nil
 }
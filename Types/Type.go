@@ -0,0 +1,118 @@
+/*
+ * Parser and Lexical Analyser Type.go
+ * Copyright (C) 2021-2023 Bas Blokzijl, Leiden, The Netherlands.
+ */
+
+package Types
+
+import (
+	"Parser-TypeChecking/Errors"
+	"Parser-TypeChecking/Parsetree"
+	"Parser-TypeChecking/Tokens"
+	"strings"
+)
+
+// Kinds of types.
+const (
+	// Base A type variable <uvar> such as A.
+	Base = iota
+	// Arrow A function type <type> '->' <type>.
+	Arrow
+	// Variable A type variable introduced by type inference, printed in lowercase such as a.
+	Variable
+)
+
+// Type
+// Structured representation of a <type>, brackets are not stored since they are implied by the structure.
+type Type struct {
+	// Kind of the type, Base, Arrow or Variable.
+	Kind int
+	// Name of a Base type or type Variable.
+	Name string
+	// Domain of an Arrow type.
+	Domain *Type
+	// Codomain of an Arrow type.
+	Codomain *Type
+}
+
+// NewBase
+// Creates the base type with the provided name.
+func NewBase(name string) *Type {
+	return &Type{Kind: Base, Name: name}
+}
+
+// NewVariable
+// Creates the type variable with the provided name.
+func NewVariable(name string) *Type {
+	return &Type{Kind: Variable, Name: name}
+}
+
+// NewArrow
+// Creates the function type from domain to codomain.
+func NewArrow(domain *Type, codomain *Type) *Type {
+	return &Type{Kind: Arrow, Domain: domain, Codomain: codomain}
+}
+
+// FromTerm
+// Converts a tree of TokenUVar and TokenFunction terms, as built by parser.TypeExpr, to a Type.
+// Returns a TypeError if the term contains other tokens.
+// term: The parsed <type>.
+func FromTerm(term *parsetree.Term) (*Type, error) {
+	switch term.Token {
+	case Tokens.TokenUVar:
+		return NewBase(term.Lexeme), nil
+	case Tokens.TokenFunction:
+		var domain, err = FromTerm(term.Children[0])
+		if err != nil {
+			return nil, err
+		}
+		codomain, err := FromTerm(term.Children[1])
+		if err != nil {
+			return nil, err
+		}
+		return NewArrow(domain, codomain), nil
+	}
+	return nil, &Errors.TypeError{Message: "Not a type: " + term.String(), Span: term.Span}
+}
+
+// Equal
+// Returns whether both types have the same structure, hence (A -> A) equals A -> A.
+// other: Type to compare with.
+func (t *Type) Equal(other *Type) bool {
+	if t == nil || other == nil {
+		return t == other
+	}
+	if t.Kind != other.Kind {
+		return false
+	}
+	if t.Kind == Arrow {
+		return t.Domain.Equal(other.Domain) && t.Codomain.Equal(other.Codomain)
+	}
+	return t.Name == other.Name
+}
+
+// String
+// Prints the type in canonical form, brackets are only used around a function type in a domain.
+func (t *Type) String() string {
+	var output strings.Builder
+	t.write(&output)
+	return output.String()
+}
+
+// write
+// Writes the type to output, used by String.
+func (t *Type) write(output *strings.Builder) {
+	if t.Kind != Arrow {
+		output.WriteString(t.Name)
+		return
+	}
+	if t.Domain.Kind == Arrow {
+		output.WriteString("(")
+		t.Domain.write(output)
+		output.WriteString(")")
+	} else {
+		t.Domain.write(output)
+	}
+	output.WriteString(" -> ")
+	t.Codomain.write(output)
+}
@@ -0,0 +1,223 @@
+/*
+ * Parser and Lexical Analyser batch.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package main
+
+import (
+	"Parser-TypeChecking/Errors"
+	"Parser-TypeChecking/Globals"
+	"Parser-TypeChecking/Parser"
+	"Parser-TypeChecking/TypeChecker"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// batch
+// Settings for analysing all lines of an input file.
+type batch struct {
+	// Name of the input file.
+	filename string
+	// Whether the type of every line is synthesized instead of checked.
+	synthesize bool
+	// Reduction strategy, -1 if the lines are not evaluated.
+	strategy int
+	// Maximum amount of reduction steps per line.
+	limit int
+	// Whether every line is reported as a JSON object.
+	json bool
+	// Directory to write the parse trees to as .dot files, empty if none are written.
+	dot string
+	// Whether the typing derivation of every line is printed.
+	tree bool
+	// Whether diagnostics are coloured.
+	colour bool
+}
+
+// Verdicts of a line that is evaluated instead of typed, these do not occur in machine readable output.
+const (
+	verdictNormalForm = "normal-form"
+	verdictStepLimit  = "step-limit"
+)
+
+// lineResult
+// Output of analysing a single judgement, kept until all judgements in front of it are printed.
+type lineResult struct {
+	// Text for the standard output.
+	output string
+	// Text for the standard error, e.g. a syntax error.
+	diagnostics string
+	// Derivation of the synthesized type, nil if the line could not be typed.
+	derivation *TypeChecker.Derivation
+	// One of verdictOK, verdictTypeError or verdictSyntaxError,
+	// or verdictNormalForm or verdictStepLimit for an evaluated line.
+	verdict string
+	// Error that stops the application, e.g. a .dot file that could not be written.
+	err error
+}
+
+// summary
+// Amount of judgements per verdict.
+type summary struct {
+	wellTyped    int
+	illTyped     int
+	syntaxErrors int
+	// Evaluated lines are not typed, they are counted by whether their normal form was reached.
+	normalForms int
+	stepLimits  int
+}
+
+// String
+// Describes the total amount of judgements and the amount of judgements per verdict,
+// the amounts of evaluated lines are only described if there are any.
+func (counts summary) String() string {
+	var evaluated = counts.normalForms + counts.stepLimits
+	var description = fmt.Sprintf("%d judgements: %d well-typed, %d ill-typed, %d syntax errors",
+		counts.wellTyped+counts.illTyped+counts.syntaxErrors+evaluated, counts.wellTyped, counts.illTyped, counts.syntaxErrors)
+	if evaluated > 0 {
+		description += fmt.Sprintf(", %d normal forms, %d reached the step limit", counts.normalForms, counts.stepLimits)
+	}
+	return description
+}
+
+// exitCode
+// Returns the exit code of the application for the amount of lines per verdict,
+// syntax errors take precedence over ill-typed lines and lines that reached the step limit.
+func (counts summary) exitCode() int {
+	if counts.syntaxErrors > 0 {
+		return exitInputError
+	}
+	if counts.illTyped > 0 || counts.stepLimits > 0 {
+		return exitIllTyped
+	}
+	return exitPassed
+}
+
+// options
+// Returns the settings of the parser for the judgement, the type is optional when synthesizing or evaluating.
+// source: The judgement to parse.
+func (settings *batch) options(source parser.Source) parser.Options {
+	return parser.Options{Line: source.Line, TypeOptional: settings.synthesize || settings.strategy >= 0}
+}
+
+// analyse
+// Parses and types a single judgement, everything that would be printed is stored in the result.
+// source: The judgement to analyse.
+// environment: Definitions in scope of the judgement, a definition in source is added to it.
+func (settings *batch) analyse(source parser.Source, environment *Globals.Environment) *lineResult {
+	var result = lineResult{verdict: verdictOK}
+	var judgement, err = parser.ParseWith(source.Text, settings.options(source))
+	if err == nil && settings.dot != "" {
+		result.err = writeDot(settings.dot, settings.filename, judgement)
+	}
+	var output bytes.Buffer
+	if settings.json {
+		var report = reportLine(source.Text, source.Line, judgement, err, environment)
+		var encoder = json.NewEncoder(&output)
+		encoder.SetEscapeHTML(false)
+		if encodeErr := encoder.Encode(report); encodeErr != nil {
+			result.err = encodeErr
+		}
+		result.output = output.String()
+		result.verdict = report.Verdict
+		result.derivation = report.derivation
+		return &result
+	}
+	if err != nil {
+		result.verdict = verdictSyntaxError
+	} else {
+		var checks = true
+		if judgement.Name != nil {
+			result.derivation, checks, err = defineLine(&output, judgement, environment)
+		} else if settings.strategy >= 0 {
+			result.verdict = verdictStepLimit
+			if evaluateLine(&output, judgement, environment, settings.strategy, settings.limit) {
+				result.verdict = verdictNormalForm
+			}
+		} else if settings.synthesize {
+			result.derivation, checks, err = synthesizeLine(&output, judgement, nil, environment)
+		} else {
+			result.derivation, checks, err = checkLine(&output, judgement, nil, environment)
+		}
+		if err != nil || !checks {
+			result.verdict = verdictTypeError
+		}
+	}
+	if result.derivation != nil && settings.tree {
+		output.WriteString(result.derivation.ASCII())
+	}
+	if err != nil {
+		// Report the error under the offending part of the line and continue with the next line.
+		result.diagnostics = Errors.Render(err, settings.filename, []rune(source.Text), settings.colour) + "\n"
+	}
+	result.output = output.String()
+	return &result
+}
+
+// run
+// Analyses all judgements with the provided amount of workers and prints the results in the order of the file.
+// Definitions are analysed in order before any later judgement is handed to a worker,
+// every judgement gets the definitions above it.
+// Returns the derivations of all judgements that could be typed and the amount of judgements per verdict.
+// sources: The judgements of the input file.
+// workers: Amount of judgements that are analysed at the same time.
+// environment: Definitions in scope of every judgement, e.g. those of imported files. The definitions
+// in sources are added to it.
+func (settings *batch) run(sources []parser.Source, workers int, environment *Globals.Environment) ([]*TypeChecker.Derivation, summary, error) {
+	var results = make([]chan *lineResult, len(sources))
+	for i := range results {
+		results[i] = make(chan *lineResult, 1)
+	}
+	type job struct {
+		index       int
+		environment *Globals.Environment
+	}
+	var jobs = make(chan job)
+	for worker := 0; worker < workers; worker++ {
+		go func() {
+			for next := range jobs {
+				results[next.index] <- settings.analyse(sources[next.index], next.environment)
+			}
+		}()
+	}
+	go func() {
+		for i := range sources {
+			if parser.IsDefinition(sources[i].Text) {
+				results[i] <- settings.analyse(sources[i], environment)
+			} else {
+				jobs <- job{i, environment.Snapshot()}
+			}
+		}
+		close(jobs)
+	}()
+	var derivations []*TypeChecker.Derivation
+	var counts summary
+	var failure error
+	for i := range sources {
+		var result = <-results[i]
+		fmt.Print(result.output)
+		fmt.Fprint(os.Stderr, result.diagnostics)
+		if result.derivation != nil {
+			derivations = append(derivations, result.derivation)
+		}
+		switch result.verdict {
+		case verdictOK:
+			counts.wellTyped++
+		case verdictSyntaxError:
+			counts.syntaxErrors++
+		case verdictNormalForm:
+			counts.normalForms++
+		case verdictStepLimit:
+			counts.stepLimits++
+		default:
+			counts.illTyped++
+		}
+		if result.err != nil && failure == nil {
+			failure = result.err
+		}
+	}
+	return derivations, counts, failure
+}
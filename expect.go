@@ -0,0 +1,115 @@
+/*
+ * Parser and Lexical Analyser expect.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package main
+
+import (
+	"Parser-TypeChecking/Imports"
+	"Parser-TypeChecking/Parser"
+	"fmt"
+	"os"
+)
+
+// Exit code of the test subcommand when at least one line does not get its expected verdict.
+const exitMismatch = 1
+
+// testResult
+// Amount of judgements per outcome of the test subcommand.
+type testResult struct {
+	passed     int
+	mismatched int
+	// Judgements without an annotation, these are not analysed.
+	unannotated int
+}
+
+// String
+// Describes the amount of lines per outcome.
+func (result testResult) String() string {
+	return fmt.Sprintf("%d passed, %d mismatched, %d without expectation",
+		result.passed, result.mismatched, result.unannotated)
+}
+
+// testFile
+// Analyses every annotated judgement of the file and prints a message for every judgement whose verdict
+// differs from the annotated verdict. Lines are parsed the same as in a run without flags, hence a judgement
+// needs a type. An unknown verdict in an annotation is reported as a mismatch of its line.
+// Definitions are analysed whether annotated or not, as later judgements may use them.
+// The imports of the file have to load without errors, these are printed to stderr.
+// filename: Name of the file with annotated lines.
+func testFile(filename string) (testResult, error) {
+	var result testResult
+	lines, err := Imports.ReadLines(filename)
+	if err != nil {
+		return result, err
+	}
+	var loader = Imports.NewLoader()
+	var sources, failures = loader.Load(filename, parser.Sources(lines))
+	for _, failure := range failures {
+		fmt.Fprintln(os.Stderr, failure.Render(false))
+	}
+	if len(failures) > 0 {
+		return result, fmt.Errorf("%s: %d errors in the imports", filename, len(failures))
+	}
+	var environment = &loader.Environment
+	// The settings of a run without flags.
+	var settings = batch{filename: filename, strategy: -1}
+	for _, source := range sources {
+		var expected = parser.Expectation(source.Text)
+		if expected == "" {
+			result.unannotated++
+			if parser.IsDefinition(source.Text) {
+				var judgement, err = parser.ParseWith(source.Text, settings.options(source))
+				reportLine(source.Text, source.Line, judgement, err, environment)
+			}
+			continue
+		}
+		var judgement, err = parser.ParseWith(source.Text, settings.options(source))
+		if expected != verdictOK && expected != verdictTypeError && expected != verdictSyntaxError {
+			// Still analyse the line, a definition on it is used by later lines.
+			reportLine(source.Text, source.Line, judgement, err, environment)
+			result.mismatched++
+			fmt.Printf("%s:%d: unknown expectation %q, use %s, %s or %s\n",
+				filename, source.Line, expected, verdictOK, verdictTypeError, verdictSyntaxError)
+			fmt.Println(source.Text)
+			continue
+		}
+		var report = reportLine(source.Text, source.Line, judgement, err, environment)
+		if report.Verdict == expected {
+			result.passed++
+			continue
+		}
+		result.mismatched++
+		fmt.Printf("%s:%d: expected %s, got %s\n", filename, source.Line, expected, report.Verdict)
+		fmt.Println(source.Text)
+		for _, reported := range report.Errors {
+			fmt.Println("    " + reported.Message)
+		}
+	}
+	return result, nil
+}
+
+// runTests
+// Runs the test subcommand: every line annotated with "-- expect: {verdict}" is checked to get that verdict.
+// Returns the exit code of the application.
+// arguments: The commandline arguments after "test".
+func runTests(arguments []string) int {
+	if len(arguments) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: Parser-TypeChecking test file...")
+		return exitInputError
+	}
+	var code = exitPassed
+	for _, filename := range arguments {
+		var result, err = testFile(filename)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitInputError
+		}
+		fmt.Printf("%s: %s\n", filename, result)
+		if result.mismatched > 0 {
+			code = exitMismatch
+		}
+	}
+	return code
+}
@@ -0,0 +1,159 @@
+/*
+ * Parser and Lexical Analyser format.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package main
+
+import (
+	"Parser-TypeChecking/Errors"
+	"Parser-TypeChecking/Globals"
+	"Parser-TypeChecking/Parsetree"
+	"Parser-TypeChecking/Tokens"
+	"Parser-TypeChecking/TypeChecker"
+	"errors"
+)
+
+// Verdicts of a line in machine readable output.
+const (
+	verdictOK          = "ok"
+	verdictTypeError   = "type-error"
+	verdictSyntaxError = "syntax-error"
+)
+
+// lineReport
+// Machine readable result of analysing a single line.
+type lineReport struct {
+	// Line number in the input file.
+	Line int `json:"line"`
+	// The analysed line.
+	Source string `json:"source"`
+	// Name of the definition, omitted if the line is not a definition.
+	Name string `json:"name,omitempty"`
+	// Parse tree of the judgement, omitted if the line could not be parsed.
+	Tree *jsonNode `json:"tree,omitempty"`
+	// Type given on the line, omitted if there is none.
+	ExpectedType string `json:"expectedType,omitempty"`
+	// Principal type of the expression, omitted if it could not be typed.
+	SynthesizedType string `json:"synthesizedType,omitempty"`
+	// One of verdictOK, verdictTypeError or verdictSyntaxError.
+	Verdict string `json:"verdict"`
+	// Errors found on the line.
+	Errors []jsonError `json:"errors"`
+	// Derivation of the synthesized type, nil if the line could not be typed. Not part of the output,
+	// it is collected for -latex.
+	derivation *TypeChecker.Derivation
+}
+
+// jsonNode
+// Node of the parse tree in machine readable output.
+type jsonNode struct {
+	// Name of the token, see Tokens.Names, or Judgement for the root.
+	Kind     string      `json:"kind"`
+	Lexeme   string      `json:"lexeme,omitempty"`
+	Start    int         `json:"start"`
+	End      int         `json:"end"`
+	Children []*jsonNode `json:"children,omitempty"`
+}
+
+// jsonError
+// Error in machine readable output, Start and End are rune offsets in the line.
+type jsonError struct {
+	// Either syntax or type.
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+}
+
+// newJSONNode
+// Converts the term and all its children.
+// term: Part of the parse tree.
+func newJSONNode(term *parsetree.Term) *jsonNode {
+	var node = jsonNode{Kind: Tokens.Names[term.Token], Lexeme: term.Lexeme, Start: term.Span.Start, End: term.Span.End}
+	for _, child := range term.Children {
+		node.Children = append(node.Children, newJSONNode(child))
+	}
+	return &node
+}
+
+// judgementNode
+// Returns the root of the parse tree of the judgement, its children are the name of a definition or the context if any,
+// the expression and the type if any.
+// judgement: The parsed line.
+func judgementNode(judgement *parsetree.Judgement) *jsonNode {
+	var root = jsonNode{Kind: "Judgement"}
+	for _, part := range []*parsetree.Term{judgement.Name, judgement.Context, judgement.Expression, judgement.Type} {
+		if part == nil {
+			continue
+		}
+		var child = newJSONNode(part)
+		if len(root.Children) == 0 {
+			root.Start = child.Start
+		}
+		root.End = child.End
+		root.Children = append(root.Children, child)
+	}
+	return &root
+}
+
+// addError
+// Adds err to the errors of the report.
+func (report *lineReport) addError(err error) {
+	var reported = jsonError{Kind: "type", Message: err.Error()}
+	var syntaxError *Errors.SyntaxError
+	if errors.As(err, &syntaxError) {
+		reported.Kind = "syntax"
+	}
+	var located Errors.Located
+	if errors.As(err, &located) {
+		reported.Start, reported.End = located.Location().Start, located.Location().End
+	}
+	report.Errors = append(report.Errors, reported)
+}
+
+// reportLine
+// Types the parsed line without printing anything and reports the result.
+// line: The analysed line.
+// number: Line number in the input file.
+// judgement: The parsed line, nil if it could not be parsed.
+// err: The syntax error found while parsing, if any.
+// environment: Definitions in scope of the line, a definition on the line is added to it.
+func reportLine(line string, number int, judgement *parsetree.Judgement, err error, environment *Globals.Environment) *lineReport {
+	var report = lineReport{Line: number, Source: line, Errors: []jsonError{}}
+	if err != nil {
+		report.Verdict = verdictSyntaxError
+		report.addError(err)
+		return &report
+	}
+	report.Tree = judgementNode(judgement)
+	var derivation *TypeChecker.Derivation
+	var checks = true
+	if judgement.Type != nil {
+		report.ExpectedType = judgement.Type.String()
+	}
+	if judgement.Name != nil {
+		report.Name = judgement.Name.Lexeme
+		derivation, checks, err = TypeChecker.DefineJudgement(judgement, environment)
+	} else if judgement.Type != nil {
+		derivation, checks, err = TypeChecker.CheckJudgementIn(judgement, nil, environment)
+	} else {
+		derivation, err = TypeChecker.SynthesizeJudgementIn(judgement, nil, environment)
+	}
+	if err != nil {
+		report.Verdict = verdictTypeError
+		report.addError(err)
+		return &report
+	}
+	report.SynthesizedType = derivation.Type.String()
+	report.derivation = derivation
+	report.Verdict = verdictOK
+	if !checks {
+		report.Verdict = verdictTypeError
+		report.addError(&Errors.TypeError{
+			Message: "Does not type check, found " + report.SynthesizedType,
+			Span:    judgement.Type.Span,
+		})
+	}
+	return &report
+}
@@ -6,55 +6,357 @@
 package main
 
 import (
+	"Parser-TypeChecking/DeBruijn"
+	"Parser-TypeChecking/Errors"
+	"Parser-TypeChecking/Evaluator"
 	"Parser-TypeChecking/Globals"
-	"Parser-TypeChecking/LexicalAnalyser"
+	"Parser-TypeChecking/Imports"
+	"Parser-TypeChecking/LanguageServer"
 	"Parser-TypeChecking/Parser"
+	"Parser-TypeChecking/Parsetree"
 	"Parser-TypeChecking/TypeChecker"
-	"bufio"
+	"Parser-TypeChecking/Types"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Exit codes of the application.
+const (
+	// Every line is well-typed.
+	exitPassed = 0
+	// Every line could be parsed, but at least one line is ill-typed.
+	exitIllTyped = 1
+	// The input could not be read or parsed, e.g. a missing file, an invalid flag or a syntax error.
+	exitInputError = 2
 )
 
 // check: Checks if the file can be opened if not,
-// an exception is throw with the corresponding error.
+// the error is printed and the application stops with exitInputError.
 // error: possible obtained error.
 func check(e error) {
 	if e != nil {
-		panic(e)
+		fmt.Fprintln(os.Stderr, e)
+		os.Exit(exitInputError)
+	}
+}
+
+// isTerminal
+// Returns whether the provided file is an interactive terminal, i.e. a character device.
+// file: The file to check, e.g. os.Stdout.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// useColour
+// Decides whether diagnostics are coloured based on the -color flag.
+// mode: One of "auto", "always" or "never".
+// output: The stream the diagnostics are written to, with "auto" they are coloured if it is a terminal.
+func useColour(mode string, output *os.File) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	return isTerminal(output) && os.Getenv("NO_COLOR") == ""
+}
+
+// contextPrefix
+// Returns the context of the judgement followed by the turnstile, empty if the judgement has no context.
+// judgement: The parsed line.
+func contextPrefix(judgement *parsetree.Judgement) string {
+	if judgement.Context == nil {
+		return ""
+	}
+	if len(judgement.Context.Children) == 0 {
+		return "⊢ "
+	}
+	return judgement.Context.String() + " ⊢ "
+}
+
+// writeDot
+// Writes the parse tree of the judgement to directory as {input}-{line}.dot.
+// directory: Directory to write to, created if it does not exist.
+// filename: Name of the input file.
+// judgement: The parsed line.
+func writeDot(directory string, filename string, judgement *parsetree.Judgement) error {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+	var base = strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	var name = fmt.Sprintf("%s-%d", base, judgement.Line)
+	return os.WriteFile(filepath.Join(directory, name+".dot"), []byte(judgement.ToDot(name)), 0644)
+}
+
+// checkLine
+// Type checks the judgement and writes the verdict followed by the judgement to output.
+// Returns the derivation of the synthesized type and whether the given type checks out.
+// output: Where the result is written to, e.g. os.Stdout.
+// judgement: The parsed line, it has to contain a type.
+// context: Assumptions in scope of the judgement besides its own, nil for none.
+// environment: Definitions in scope of the judgement, nil for none.
+func checkLine(output io.Writer, judgement *parsetree.Judgement, context *Globals.VarTypeList, environment *Globals.Environment) (*TypeChecker.Derivation, bool, error) {
+	var derivation, checks, err = TypeChecker.CheckJudgementIn(judgement, context, environment)
+	if err != nil {
+		return nil, false, err
+	}
+	if checks {
+		fmt.Fprintln(output, "Type checks out")
+	} else {
+		fmt.Fprintln(output, "Does not type check")
+	}
+	fmt.Fprintln(output, contextPrefix(judgement)+judgement.Expression.String()+" : "+judgement.Type.String())
+	return derivation, checks, nil
+}
+
+// synthesizeLine
+// Writes the expression of the judgement with its synthesized type to output.
+// If the line also provides a type, the verdict whether it is an instance of the synthesized type is written as well.
+// Returns the derivation of the synthesized type and whether the given type checks out, true if there is none.
+// output: Where the result is written to, e.g. os.Stdout.
+// judgement: The parsed line.
+// context: Assumptions in scope of the judgement besides its own, nil for none.
+// environment: Definitions in scope of the judgement, nil for none.
+func synthesizeLine(output io.Writer, judgement *parsetree.Judgement, context *Globals.VarTypeList, environment *Globals.Environment) (*TypeChecker.Derivation, bool, error) {
+	var derivation, err = TypeChecker.SynthesizeJudgementIn(judgement, context, environment)
+	if err != nil {
+		return nil, false, err
+	}
+	var foundType = derivation.Type
+	fmt.Fprintln(output, contextPrefix(judgement)+judgement.Expression.String()+" : "+foundType.String())
+	if judgement.Type == nil {
+		return derivation, true, nil
+	}
+	expectedType, err := Types.FromTerm(judgement.Type)
+	if err != nil {
+		return nil, false, err
+	}
+	// The given type has to be an instance of the principal type, the same as in Check.
+	if TypeChecker.NewInference().Unify(foundType, expectedType) == nil {
+		fmt.Fprintln(output, "Type checks out")
+		return derivation, true, nil
+	}
+	fmt.Fprintln(output, "Does not type check, expected "+expectedType.String())
+	return derivation, false, nil
+}
+
+// defineLine
+// Types the definition and adds it to the environment, writes the verdict followed by the definition
+// with its type to output. Returns the derivation of the synthesized type and whether the given type checks out.
+// output: Where the result is written to, e.g. os.Stdout.
+// judgement: The parsed definition.
+// environment: Definitions in scope of the definition, the definition is added to it.
+func defineLine(output io.Writer, judgement *parsetree.Judgement, environment *Globals.Environment) (*TypeChecker.Derivation, bool, error) {
+	var derivation, checks, err = TypeChecker.DefineJudgement(judgement, environment)
+	if err != nil {
+		return nil, false, err
+	}
+	var definition = "def " + judgement.Name.Lexeme + " = " + judgement.Expression.String()
+	if !checks {
+		fmt.Fprintln(output, "Does not type check")
+		fmt.Fprintln(output, definition+" : "+judgement.Type.String())
+		return derivation, false, nil
 	}
+	fmt.Fprintln(output, "Defined "+judgement.Name.Lexeme)
+	fmt.Fprintln(output, definition+" : "+environment.Lookup(judgement.Name.Lexeme).Type.String())
+	return derivation, true, nil
+}
+
+// evaluateLine
+// Writes every beta reduction step of the expression of the judgement to output,
+// the definitions used by the expression are unfolded first.
+// output: Where the steps are written to, e.g. os.Stdout.
+// judgement: The parsed line.
+// environment: Definitions in scope of the judgement, nil for none.
+// strategy: The reduction strategy to use.
+// limit: Maximum amount of reduction steps.
+// Returns whether the normal form was reached within limit steps.
+func evaluateLine(output io.Writer, judgement *parsetree.Judgement, environment *Globals.Environment, strategy int, limit int) bool {
+	var steps, limitErr = Evaluator.Evaluate(Evaluator.Unfold(judgement.Expression, environment), strategy, limit)
+	fmt.Fprintln(output, steps[0].String())
+	for _, step := range steps[1:] {
+		fmt.Fprintln(output, "  => "+step.String())
+	}
+	if limitErr != nil {
+		fmt.Fprintln(output, limitErr)
+		return false
+	}
+	fmt.Fprintf(output, "Normal form reached after %d steps\n", len(steps)-1)
+	return true
+}
+
+// parseLineNumbers
+// Parses the argument of the -alpha flag, two line numbers separated by a comma.
+// argument: The provided argument, e.g. "1,2".
+func parseLineNumbers(argument string) (int, int, error) {
+	var first, second int
+	var parts = strings.Split(argument, ",")
+	if len(parts) == 2 {
+		var firstErr, secondErr error
+		first, firstErr = strconv.Atoi(strings.TrimSpace(parts[0]))
+		second, secondErr = strconv.Atoi(strings.TrimSpace(parts[1]))
+		if firstErr == nil && secondErr == nil && first > 0 && second > 0 {
+			return first, second, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("expected two line numbers separated by a comma, e.g. -alpha 1,2, found %q", argument)
+}
+
+// compareLines
+// Parses the judgements that start on two lines of the file as expressions and prints whether they are
+// alpha-equivalent, together with their de Bruijn representation.
+// filename: Name of the input file.
+// first: Number of the first line, starting at 1.
+// second: Number of the second line, starting at 1.
+// colour: Whether diagnostics are coloured.
+func compareLines(filename string, first int, second int, colour bool) error {
+	lines, err := Imports.ReadLines(filename)
+	if err != nil {
+		return err
+	}
+	var sources = parser.Sources(lines)
+	var expressions []*parsetree.Term
+	for _, number := range []int{first, second} {
+		var source *parser.Source
+		for i := range sources {
+			if sources[i].Line == number {
+				source = &sources[i]
+			}
+		}
+		if source == nil {
+			return fmt.Errorf("%s has no judgement that starts on line %d", filename, number)
+		}
+		var judgement, err = parser.ParseWith(source.Text, parser.Options{Line: number, TypeOptional: true})
+		if err != nil {
+			return errors.New(Errors.Render(err, filename, []rune(source.Text), colour))
+		}
+		var expression = judgement.Expression
+		fmt.Printf("%d: %s  =  %s\n", number, expression.String(), DeBruijn.FromTerm(expression).String())
+		expressions = append(expressions, expression)
+	}
+	if DeBruijn.AlphaEquivalent(expressions[0], expressions[1]) {
+		fmt.Println("alpha-equivalent")
+	} else {
+		fmt.Println("not alpha-equivalent")
+	}
+	return nil
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "test" {
+		os.Exit(runTests(os.Args[2:]))
+	}
+	var colourMode = flag.String("color", "auto", "colour diagnostics: auto, always or never")
+	var synthesize = flag.Bool("synth", false, "print the synthesized type of every line instead of checking a given type")
+	var evaluate = flag.String("eval", "", "print the beta reduction steps of every line using the strategy: normal, applicative, cbn or cbv")
+	var stepLimit = flag.Int("steps", 100, "maximum amount of beta reduction steps per line")
+	var format = flag.String("format", "text", "output format: text or json, json prints one object per line")
+	var dot = flag.String("dot", "", "write the parse tree of every line as a Graphviz .dot file to the provided directory")
+	var tree = flag.Bool("tree", false, "print the typing derivation of every line that can be typed")
+	var latex = flag.String("latex", "", "write the typing derivations as a LaTeX bussproofs document to the provided file")
+	var interactive = flag.Bool("repl", false, "start an interactive session instead of reading a file")
+	var lsp = flag.Bool("lsp", false, "run as a language server that communicates over stdin and stdout")
+	var workers = flag.Int("j", 1, "amount of lines that are analysed in parallel")
+	var alpha = flag.String("alpha", "", "compare two lines of the file for alpha-equivalence, e.g. -alpha 1,2")
+	flag.Parse()
 	// get command arguments provided.
-	commandArgs := os.Args
+	commandArgs := flag.Args()
 
-	if len(commandArgs) == 1 {
-		fmt.Printf("Please provide a filename in the commandline")
+	if *lsp {
+		if err := LanguageServer.NewServer(os.Stdin, os.Stdout).Serve(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitInputError)
+		}
 		return
 	}
-	if len(commandArgs) > 2 {
+	if *interactive {
+		var strategy = Evaluator.NormalOrder
+		if *evaluate != "" {
+			var err error
+			if strategy, err = Evaluator.ParseStrategy(*evaluate); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		repl(os.Stdin, useColour(*colourMode, os.Stderr), strategy, *stepLimit)
+		return
+	}
+	if len(commandArgs) == 0 {
+		fmt.Printf("Please provide a filename in the commandline")
+		os.Exit(exitInputError)
+	}
+	if len(commandArgs) > 1 {
 		fmt.Printf("Too many arguments provided, please only provde the filename used as input!")
+		os.Exit(exitInputError)
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Printf("Unknown output format %q, use text or json\n", *format)
+		os.Exit(exitInputError)
+	}
+	if *workers < 1 {
+		fmt.Println("The amount of workers (-j) has to be at least 1")
+		os.Exit(exitInputError)
+	}
+	if *format == "json" && *evaluate != "" {
+		fmt.Println("The json format cannot be combined with -eval")
+		os.Exit(exitInputError)
+	}
+	// The derivations are printed as text, which would break the json output.
+	if *format == "json" && *tree {
+		fmt.Println("The json format cannot be combined with -tree")
+		os.Exit(exitInputError)
+	}
+	var colour = useColour(*colourMode, os.Stderr)
+	var strategy = -1
+	if *evaluate != "" {
+		var err error
+		if strategy, err = Evaluator.ParseStrategy(*evaluate); err != nil {
+			fmt.Println(err)
+			os.Exit(exitInputError)
+		}
+	}
+	if *alpha != "" {
+		var first, second, err = parseLineNumbers(*alpha)
+		if err == nil {
+			err = compareLines(commandArgs[0], first, second, colour)
+		}
+		check(err)
 		return
 	}
-	// Open the file as provided by the commandline arguments
-	data, err := os.Open(commandArgs[1])
+	// Read the file as provided by the commandline arguments, every judgement is analysed on its own.
+	lines, err := Imports.ReadLines(commandArgs[0])
 	check(err)
-	var variables = new(Globals.Vars)
-	// Initiate a bufio scanner to analyse the data line by line.
-	var scanner = bufio.NewScanner(data)
-	for scanner.Scan() {
-		variables.CurrentLine = []rune(scanner.Text())
-		variables.CountBrackets = 0
-		variables.ExpectVariable = false
-		variables.DebugMode = false
-		variables.Index = -1
-		variables.Tree.IndexDoubleDot = -1
-		variables.Token = LexicalAnalyser.LexicalAnalyser(variables)
-		parser.Judgement(variables)
-		TypeChecker.TypeChecker(variables)
-		fmt.Println(variables.Tree.SubTreeToStandardOutput(0))
-		variables.Tree.ClearTree()
-
+	// The definitions of imported files are in scope of every judgement, the file is not analysed if an import fails.
+	var loader = Imports.NewLoader()
+	var sources, failures = loader.Load(commandArgs[0], parser.Sources(lines))
+	for _, failure := range failures {
+		fmt.Fprintln(os.Stderr, failure.Render(colour))
+	}
+	if len(failures) > 0 {
+		os.Exit(exitInputError)
+	}
+	var settings = batch{filename: commandArgs[0], synthesize: *synthesize, strategy: strategy, limit: *stepLimit,
+		json: *format == "json", dot: *dot, tree: *tree, colour: colour}
+	derivations, counts, err := settings.run(sources, *workers, &loader.Environment)
+	check(err)
+	if settings.json {
+		fmt.Fprintln(os.Stderr, counts)
+	} else {
+		fmt.Println(counts)
+	}
+	if *latex != "" {
+		check(os.WriteFile(*latex, []byte(TypeChecker.LaTeXDocument(derivations)), 0644))
 	}
+	os.Exit(counts.exitCode())
 
 } // main
@@ -0,0 +1,210 @@
+/*
+ * Parser and Lexical Analyser repl.go
+ * Copyright (C) 2021-2023 Bas Blokzijl Leiden, The Netherlands.
+ */
+
+package main
+
+import (
+	"Parser-TypeChecking/Errors"
+	"Parser-TypeChecking/Globals"
+	"Parser-TypeChecking/Parser"
+	"Parser-TypeChecking/Parsetree"
+	"Parser-TypeChecking/Tokens"
+	"Parser-TypeChecking/Types"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// replHelp is printed by the :help command.
+const replHelp = `Enter a judgement, e.g. \x^A x : A -> A, to type check it. Commands:
+  :type e       print the synthesized type of expression e
+  :tree e       print the typing derivation of expression e
+  :eval e       print the beta reduction steps of expression e
+  :debug on|off print the steps of the parser
+  :assume x : T add the assumption x : T to the context of the session
+  :ctx          print the context of the session
+  :defs         print the definitions of the session, add one with def name = e
+  :clear        remove all assumptions from the context of the session
+  :history      print the entered lines
+  :help         print this message
+  :quit         end the session`
+
+// session
+// State of an interactive session.
+type session struct {
+	// Whether the steps of the parser are printed.
+	debug bool
+	// Assumptions in scope of every judgement, besides the context of the judgement itself.
+	context Globals.VarTypeList
+	// Definitions entered with def, in scope of every later judgement.
+	environment Globals.Environment
+	// All entered lines, in order.
+	history []string
+	// Whether diagnostics are coloured.
+	colour bool
+	// Reduction strategy used by :eval.
+	strategy int
+	// Maximum amount of reduction steps used by :eval.
+	limit int
+}
+
+// repl
+// Reads lines from input and analyses every line until the input ends or :quit is entered.
+// input: Source of the lines, e.g. os.Stdin.
+// colour: Whether diagnostics are coloured.
+// strategy: Reduction strategy used by :eval.
+// limit: Maximum amount of reduction steps used by :eval.
+func repl(input io.Reader, colour bool, strategy int, limit int) {
+	var state = session{colour: colour, strategy: strategy, limit: limit}
+	var prompt = input == os.Stdin && isTerminal(os.Stdin)
+	var scanner = bufio.NewScanner(input)
+	for {
+		if prompt {
+			fmt.Print("> ")
+		}
+		if !scanner.Scan() {
+			return
+		}
+		var line = scanner.Text()
+		// A line that ends with a '\' is continued on the next line.
+		for parser.Continued(line) {
+			if prompt {
+				fmt.Print("... ")
+			}
+			if !scanner.Scan() {
+				break
+			}
+			line += "\n" + scanner.Text()
+		}
+		line = strings.TrimSpace(line)
+		if parser.IsBlank(line) {
+			continue
+		}
+		state.history = append(state.history, line)
+		if line == ":quit" || line == ":q" {
+			return
+		}
+		state.run(line)
+	}
+}
+
+// run
+// Executes a single command or judgement.
+// line: The entered line without surrounding whitespace.
+func (state *session) run(line string) {
+	var command, argument = line, ""
+	if strings.HasPrefix(line, ":") {
+		if index := strings.IndexAny(line, " \t"); index >= 0 {
+			command, argument = line[:index], strings.TrimSpace(line[index:])
+		}
+	} else {
+		command, argument = "", line
+	}
+	switch command {
+	case "":
+		state.analyse(argument, false, func(judgement *parsetree.Judgement) error {
+			if judgement.Name != nil {
+				var _, _, err = defineLine(os.Stdout, judgement, &state.environment)
+				return err
+			}
+			var _, _, err = checkLine(os.Stdout, judgement, &state.context, &state.environment)
+			return err
+		})
+	case ":type", ":t":
+		state.analyse(argument, true, func(judgement *parsetree.Judgement) error {
+			var _, _, err = synthesizeLine(os.Stdout, judgement, &state.context, &state.environment)
+			return err
+		})
+	case ":tree":
+		state.analyse(argument, true, func(judgement *parsetree.Judgement) error {
+			var derivation, _, err = synthesizeLine(os.Stdout, judgement, &state.context, &state.environment)
+			if err == nil {
+				fmt.Print(derivation.ASCII())
+			}
+			return err
+		})
+	case ":eval", ":e":
+		state.analyse(argument, true, func(judgement *parsetree.Judgement) error {
+			evaluateLine(os.Stdout, judgement, &state.environment, state.strategy, state.limit)
+			return nil
+		})
+	case ":debug":
+		switch argument {
+		case "on":
+			state.debug = true
+		case "off":
+			state.debug = false
+		default:
+			fmt.Println("Use :debug on or :debug off")
+		}
+	case ":assume":
+		state.analyse(argument, false, state.assume)
+	case ":ctx":
+		var assumptions = state.context.Entries()
+		if len(assumptions) == 0 {
+			fmt.Println("Context is empty")
+		}
+		for _, assumption := range assumptions {
+			fmt.Println(assumption.VarName + " : " + assumption.Type.String())
+		}
+	case ":defs":
+		var definitions = state.environment.Entries()
+		if len(definitions) == 0 {
+			fmt.Println("No definitions")
+		}
+		for _, definition := range definitions {
+			fmt.Println(definition.Name.Lexeme + " : " + definition.Type.String())
+		}
+	case ":clear":
+		state.context = Globals.VarTypeList{}
+	case ":history":
+		for i, entry := range state.history {
+			fmt.Printf("%4d  %s\n", i+1, entry)
+		}
+	case ":help", ":h", ":?":
+		fmt.Println(replHelp)
+	default:
+		fmt.Println("Unknown command " + command + ", enter :help for a list of commands")
+	}
+}
+
+// analyse
+// Parses line, runs analysis on it and reports an error under the offending part of the line.
+// line: The line to analyse.
+// typeOptional: Whether the line may omit the ':' and type.
+// analysis: Types the parsed line, e.g. checkLine.
+func (state *session) analyse(line string, typeOptional bool, analysis func(*parsetree.Judgement) error) {
+	if line == "" {
+		fmt.Println("Missing expression, enter :help for a list of commands")
+		return
+	}
+	var options = parser.Options{Line: len(state.history), TypeOptional: typeOptional, Debug: state.debug}
+	var judgement, err = parser.ParseWith(line, options)
+	if err == nil {
+		err = analysis(judgement)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, Errors.Render(err, "repl", []rune(line), state.colour))
+	}
+}
+
+// assume
+// Adds the assumption x : T, parsed as a judgement, to the context of the session.
+// Later assumptions for the same variable shadow earlier ones.
+// judgement: The parsed assumption.
+func (state *session) assume(judgement *parsetree.Judgement) error {
+	if judgement.Context != nil || judgement.Expression.Token != Tokens.TokenVariable {
+		return &Errors.SyntaxError{Kind: Errors.ExpectedVariable, Message: "Expected an assumption x : T.",
+			Span: judgement.Expression.Span}
+	}
+	var assumed, err = Types.FromTerm(judgement.Type)
+	if err != nil {
+		return err
+	}
+	state.context.AddVarType(judgement.Expression.Lexeme, assumed)
+	return nil
+}